@@ -0,0 +1,383 @@
+package lex
+
+import (
+	"math/big"
+	"time"
+)
+
+//extend extends dst by n bytes, reusing its capacity if sufficient, returning the extended slice and the n bytes added.
+func extend(dst []byte, n int) ([]byte, []byte) {
+	m := len(dst)
+	dst = append(dst, make([]byte, n)...)
+	return dst, dst[m:]
+}
+
+//
+
+//AppendBool appends v to dst, as per PutBool, returning the extended slice.
+func AppendBool(dst []byte, v bool) []byte {
+	dst, b := extend(dst, 1)
+	PutBool(b, v)
+	return dst
+}
+
+//AppendUint8 appends v to dst, as per PutUint8, returning the extended slice.
+func AppendUint8(dst []byte, v uint8) []byte {
+	dst, b := extend(dst, 1)
+	PutUint8(b, v)
+	return dst
+}
+
+//AppendUint16 appends v to dst, as per PutUint16, returning the extended slice.
+func AppendUint16(dst []byte, v uint16) []byte {
+	dst, b := extend(dst, 2)
+	PutUint16(b, v)
+	return dst
+}
+
+//AppendUint32 appends v to dst, as per PutUint32, returning the extended slice.
+func AppendUint32(dst []byte, v uint32) []byte {
+	dst, b := extend(dst, 4)
+	PutUint32(b, v)
+	return dst
+}
+
+//AppendUint64 appends v to dst, as per PutUint64, returning the extended slice.
+func AppendUint64(dst []byte, v uint64) []byte {
+	dst, b := extend(dst, 8)
+	PutUint64(b, v)
+	return dst
+}
+
+//AppendInt8 appends v to dst, as per PutInt8, returning the extended slice.
+func AppendInt8(dst []byte, v int8) []byte {
+	dst, b := extend(dst, 1)
+	PutInt8(b, v)
+	return dst
+}
+
+//AppendInt16 appends v to dst, as per PutInt16, returning the extended slice.
+func AppendInt16(dst []byte, v int16) []byte {
+	dst, b := extend(dst, 2)
+	PutInt16(b, v)
+	return dst
+}
+
+//AppendInt32 appends v to dst, as per PutInt32, returning the extended slice.
+func AppendInt32(dst []byte, v int32) []byte {
+	dst, b := extend(dst, 4)
+	PutInt32(b, v)
+	return dst
+}
+
+//AppendInt64 appends v to dst, as per PutInt64, returning the extended slice.
+func AppendInt64(dst []byte, v int64) []byte {
+	dst, b := extend(dst, 8)
+	PutInt64(b, v)
+	return dst
+}
+
+//AppendFloat32 appends v to dst, as per PutFloat32, returning the extended slice.
+func AppendFloat32(dst []byte, v float32) []byte {
+	dst, b := extend(dst, 4)
+	PutFloat32(b, v)
+	return dst
+}
+
+//AppendFloat64 appends v to dst, as per PutFloat64, returning the extended slice.
+func AppendFloat64(dst []byte, v float64) []byte {
+	dst, b := extend(dst, 8)
+	PutFloat64(b, v)
+	return dst
+}
+
+//AppendComplex64 appends v to dst, as per PutComplex64, returning the extended slice.
+func AppendComplex64(dst []byte, v complex64) []byte {
+	dst, b := extend(dst, 8)
+	PutComplex64(b, v)
+	return dst
+}
+
+//AppendComplex128 appends v to dst, as per PutComplex128, returning the extended slice.
+func AppendComplex128(dst []byte, v complex128) []byte {
+	dst, b := extend(dst, 16)
+	PutComplex128(b, v)
+	return dst
+}
+
+//AppendByte appends v to dst, as per PutByte, returning the extended slice.
+func AppendByte(dst []byte, v byte) []byte {
+	dst, b := extend(dst, 1)
+	PutByte(b, v)
+	return dst
+}
+
+//AppendRune appends v to dst, as per PutRune, returning the extended slice.
+func AppendRune(dst []byte, v rune) []byte {
+	dst, b := extend(dst, 4)
+	PutRune(b, v)
+	return dst
+}
+
+//AppendUint appends v to dst, as per PutUint, returning the extended slice.
+func AppendUint(dst []byte, v uint) []byte {
+	dst, b := extend(dst, 8)
+	PutUint(b, v)
+	return dst
+}
+
+//AppendInt appends v to dst, as per PutInt, returning the extended slice.
+func AppendInt(dst []byte, v int) []byte {
+	dst, b := extend(dst, 8)
+	PutInt(b, v)
+	return dst
+}
+
+//AppendString appends v to dst, as per PutString, returning the extended slice.
+func AppendString(dst []byte, v string) []byte {
+	dst, b := extend(dst, len(v)+1)
+	PutString(b, v)
+	return dst
+}
+
+//
+
+//AppendBoolDesc appends v to dst, as per PutBoolDesc, returning the extended slice.
+func AppendBoolDesc(dst []byte, v bool) []byte {
+	dst, b := extend(dst, 1)
+	PutBoolDesc(b, v)
+	return dst
+}
+
+//AppendUint8Desc appends v to dst, as per PutUint8Desc, returning the extended slice.
+func AppendUint8Desc(dst []byte, v uint8) []byte {
+	dst, b := extend(dst, 1)
+	PutUint8Desc(b, v)
+	return dst
+}
+
+//AppendUint16Desc appends v to dst, as per PutUint16Desc, returning the extended slice.
+func AppendUint16Desc(dst []byte, v uint16) []byte {
+	dst, b := extend(dst, 2)
+	PutUint16Desc(b, v)
+	return dst
+}
+
+//AppendUint32Desc appends v to dst, as per PutUint32Desc, returning the extended slice.
+func AppendUint32Desc(dst []byte, v uint32) []byte {
+	dst, b := extend(dst, 4)
+	PutUint32Desc(b, v)
+	return dst
+}
+
+//AppendUint64Desc appends v to dst, as per PutUint64Desc, returning the extended slice.
+func AppendUint64Desc(dst []byte, v uint64) []byte {
+	dst, b := extend(dst, 8)
+	PutUint64Desc(b, v)
+	return dst
+}
+
+//AppendInt8Desc appends v to dst, as per PutInt8Desc, returning the extended slice.
+func AppendInt8Desc(dst []byte, v int8) []byte {
+	dst, b := extend(dst, 1)
+	PutInt8Desc(b, v)
+	return dst
+}
+
+//AppendInt16Desc appends v to dst, as per PutInt16Desc, returning the extended slice.
+func AppendInt16Desc(dst []byte, v int16) []byte {
+	dst, b := extend(dst, 2)
+	PutInt16Desc(b, v)
+	return dst
+}
+
+//AppendInt32Desc appends v to dst, as per PutInt32Desc, returning the extended slice.
+func AppendInt32Desc(dst []byte, v int32) []byte {
+	dst, b := extend(dst, 4)
+	PutInt32Desc(b, v)
+	return dst
+}
+
+//AppendInt64Desc appends v to dst, as per PutInt64Desc, returning the extended slice.
+func AppendInt64Desc(dst []byte, v int64) []byte {
+	dst, b := extend(dst, 8)
+	PutInt64Desc(b, v)
+	return dst
+}
+
+//AppendFloat32Desc appends v to dst, as per PutFloat32Desc, returning the extended slice.
+func AppendFloat32Desc(dst []byte, v float32) []byte {
+	dst, b := extend(dst, 4)
+	PutFloat32Desc(b, v)
+	return dst
+}
+
+//AppendFloat64Desc appends v to dst, as per PutFloat64Desc, returning the extended slice.
+func AppendFloat64Desc(dst []byte, v float64) []byte {
+	dst, b := extend(dst, 8)
+	PutFloat64Desc(b, v)
+	return dst
+}
+
+//AppendComplex64Desc appends v to dst, as per PutComplex64Desc, returning the extended slice.
+func AppendComplex64Desc(dst []byte, v complex64) []byte {
+	dst, b := extend(dst, 8)
+	PutComplex64Desc(b, v)
+	return dst
+}
+
+//AppendComplex128Desc appends v to dst, as per PutComplex128Desc, returning the extended slice.
+func AppendComplex128Desc(dst []byte, v complex128) []byte {
+	dst, b := extend(dst, 16)
+	PutComplex128Desc(b, v)
+	return dst
+}
+
+//AppendByteDesc appends v to dst, as per PutByteDesc, returning the extended slice.
+func AppendByteDesc(dst []byte, v byte) []byte {
+	dst, b := extend(dst, 1)
+	PutByteDesc(b, v)
+	return dst
+}
+
+//AppendRuneDesc appends v to dst, as per PutRuneDesc, returning the extended slice.
+func AppendRuneDesc(dst []byte, v rune) []byte {
+	dst, b := extend(dst, 4)
+	PutRuneDesc(b, v)
+	return dst
+}
+
+//AppendUintDesc appends v to dst, as per PutUintDesc, returning the extended slice.
+func AppendUintDesc(dst []byte, v uint) []byte {
+	dst, b := extend(dst, 8)
+	PutUintDesc(b, v)
+	return dst
+}
+
+//AppendIntDesc appends v to dst, as per PutIntDesc, returning the extended slice.
+func AppendIntDesc(dst []byte, v int) []byte {
+	dst, b := extend(dst, 8)
+	PutIntDesc(b, v)
+	return dst
+}
+
+//AppendStringDesc appends v to dst, as per PutStringDesc, returning the extended slice.
+func AppendStringDesc(dst []byte, v string) []byte {
+	dst, b := extend(dst, len(v)+1)
+	PutStringDesc(b, v)
+	return dst
+}
+
+//
+
+//AppendEscapedString appends v to dst, as per PutEscapedString, returning the extended slice.
+func AppendEscapedString(dst []byte, v string) []byte {
+	dst, b := extend(dst, EscapedStringSize(v))
+	PutEscapedString(b, v)
+	return dst
+}
+
+//AppendEscapedStringDesc appends v to dst, as per PutEscapedStringDesc, returning the extended slice.
+func AppendEscapedStringDesc(dst []byte, v string) []byte {
+	dst, b := extend(dst, EscapedStringSize(v))
+	PutEscapedStringDesc(b, v)
+	return dst
+}
+
+//AppendEscapedBytes appends v to dst, as per PutEscapedBytes, returning the extended slice.
+func AppendEscapedBytes(dst []byte, v []byte) []byte {
+	dst, b := extend(dst, EscapedBytesSize(v))
+	PutEscapedBytes(b, v)
+	return dst
+}
+
+//AppendEscapedBytesDesc appends v to dst, as per PutEscapedBytesDesc, returning the extended slice.
+func AppendEscapedBytesDesc(dst []byte, v []byte) []byte {
+	dst, b := extend(dst, EscapedBytesSize(v))
+	PutEscapedBytesDesc(b, v)
+	return dst
+}
+
+//
+
+//AppendUvarint appends v to dst, as per PutUvarint, returning the extended slice.
+func AppendUvarint(dst []byte, v uint64) []byte {
+	dst, b := extend(dst, UvarintSize(v))
+	PutUvarint(b, v)
+	return dst
+}
+
+//AppendVarint appends v to dst, as per PutVarint, returning the extended slice.
+func AppendVarint(dst []byte, v int64) []byte {
+	dst, b := extend(dst, VarintSize(v))
+	PutVarint(b, v)
+	return dst
+}
+
+//AppendNumberInt64 appends v to dst, as per PutNumberInt64, returning the extended slice.
+func AppendNumberInt64(dst []byte, v int64) []byte {
+	dst, b := extend(dst, NumberInt64Size(v))
+	PutNumberInt64(b, v)
+	return dst
+}
+
+//AppendNumberUint64 appends v to dst, as per PutNumberUint64, returning the extended slice.
+func AppendNumberUint64(dst []byte, v uint64) []byte {
+	dst, b := extend(dst, NumberUint64Size(v))
+	PutNumberUint64(b, v)
+	return dst
+}
+
+//AppendNumberFloat64 appends v to dst, as per PutNumberFloat64, returning the extended slice.
+func AppendNumberFloat64(dst []byte, v float64) []byte {
+	dst, b := extend(dst, NumberFloat64Size(v))
+	PutNumberFloat64(b, v)
+	return dst
+}
+
+//
+
+//AppendTime appends v to dst at precision p, as per PutTime, returning the extended slice.
+func AppendTime(dst []byte, v time.Time, p time.Duration) []byte {
+	checkPrecision(p)
+	dst, b := extend(dst, TimeSize(p))
+	PutTime(b, v, p)
+	return dst
+}
+
+//AppendBigInt appends v to dst, as per PutBigInt, returning the extended slice.
+func AppendBigInt(dst []byte, v *big.Int) []byte {
+	dst, b := extend(dst, BigIntSize(v))
+	PutBigInt(b, v)
+	return dst
+}
+
+//AppendDecimal appends v to dst, as per PutDecimal, returning the extended slice.
+func AppendDecimal(dst []byte, v Decimal) []byte {
+	dst, b := extend(dst, DecimalSize(v))
+	PutDecimal(b, v)
+	return dst
+}
+
+//
+
+//AppendMemcomparableBytes appends v to dst, as per PutMemcomparableBytes, returning the extended slice.
+func AppendMemcomparableBytes(dst []byte, v []byte) []byte {
+	dst, b := extend(dst, MemcomparableBytesSize(v))
+	PutMemcomparableBytes(b, v)
+	return dst
+}
+
+//AppendMemcomparableBytesDesc appends v to dst, as per PutMemcomparableBytesDesc, returning the extended slice.
+func AppendMemcomparableBytesDesc(dst []byte, v []byte) []byte {
+	dst, b := extend(dst, MemcomparableBytesSize(v))
+	PutMemcomparableBytesDesc(b, v)
+	return dst
+}
+
+//AppendMemcomparableFloat64 appends v to dst, as per PutMemcomparableFloat64, returning the extended slice.
+func AppendMemcomparableFloat64(dst []byte, v float64) []byte {
+	dst, b := extend(dst, 8)
+	PutMemcomparableFloat64(b, v)
+	return dst
+}
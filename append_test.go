@@ -0,0 +1,98 @@
+package lex
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppend(t *testing.T) {
+	prefix := func() []byte { return []byte{0xAB} }
+	s := "a\x00b"
+	bs := []byte(s)
+	x := big.NewInt(-300)
+	d, _ := ParseDecimal("-1.25")
+	tm := time.Unix(1, 2)
+
+	for i, c := range []struct {
+		actual []byte
+		n      int
+		put    func(b []byte)
+	}{
+		{AppendBool(prefix(), true), 1, func(b []byte) { PutBool(b, true) }},
+		{AppendUint8(prefix(), uint8(42)), 1, func(b []byte) { PutUint8(b, uint8(42)) }},
+		{AppendUint16(prefix(), uint16(42)), 2, func(b []byte) { PutUint16(b, uint16(42)) }},
+		{AppendUint32(prefix(), uint32(42)), 4, func(b []byte) { PutUint32(b, uint32(42)) }},
+		{AppendUint64(prefix(), uint64(42)), 8, func(b []byte) { PutUint64(b, uint64(42)) }},
+		{AppendInt8(prefix(), int8(-42)), 1, func(b []byte) { PutInt8(b, int8(-42)) }},
+		{AppendInt16(prefix(), int16(-42)), 2, func(b []byte) { PutInt16(b, int16(-42)) }},
+		{AppendInt32(prefix(), int32(-42)), 4, func(b []byte) { PutInt32(b, int32(-42)) }},
+		{AppendInt64(prefix(), int64(-42)), 8, func(b []byte) { PutInt64(b, int64(-42)) }},
+		{AppendFloat32(prefix(), float32(-4.2)), 4, func(b []byte) { PutFloat32(b, float32(-4.2)) }},
+		{AppendFloat64(prefix(), -4.2), 8, func(b []byte) { PutFloat64(b, -4.2) }},
+		{AppendComplex64(prefix(), complex64(1+2i)), 8, func(b []byte) { PutComplex64(b, complex64(1+2i)) }},
+		{AppendComplex128(prefix(), 1+2i), 16, func(b []byte) { PutComplex128(b, 1+2i) }},
+		{AppendByte(prefix(), byte(42)), 1, func(b []byte) { PutByte(b, byte(42)) }},
+		{AppendRune(prefix(), 'x'), 4, func(b []byte) { PutRune(b, 'x') }},
+		{AppendUint(prefix(), uint(42)), 8, func(b []byte) { PutUint(b, uint(42)) }},
+		{AppendInt(prefix(), -42), 8, func(b []byte) { PutInt(b, -42) }},
+		{AppendString(prefix(), s), len(s) + 1, func(b []byte) { PutString(b, s) }},
+		{AppendBoolDesc(prefix(), true), 1, func(b []byte) { PutBoolDesc(b, true) }},
+		{AppendUint8Desc(prefix(), uint8(42)), 1, func(b []byte) { PutUint8Desc(b, uint8(42)) }},
+		{AppendUint16Desc(prefix(), uint16(42)), 2, func(b []byte) { PutUint16Desc(b, uint16(42)) }},
+		{AppendUint32Desc(prefix(), uint32(42)), 4, func(b []byte) { PutUint32Desc(b, uint32(42)) }},
+		{AppendUint64Desc(prefix(), uint64(42)), 8, func(b []byte) { PutUint64Desc(b, uint64(42)) }},
+		{AppendInt8Desc(prefix(), int8(-42)), 1, func(b []byte) { PutInt8Desc(b, int8(-42)) }},
+		{AppendInt16Desc(prefix(), int16(-42)), 2, func(b []byte) { PutInt16Desc(b, int16(-42)) }},
+		{AppendInt32Desc(prefix(), int32(-42)), 4, func(b []byte) { PutInt32Desc(b, int32(-42)) }},
+		{AppendInt64Desc(prefix(), int64(-42)), 8, func(b []byte) { PutInt64Desc(b, int64(-42)) }},
+		{AppendFloat32Desc(prefix(), float32(-4.2)), 4, func(b []byte) { PutFloat32Desc(b, float32(-4.2)) }},
+		{AppendFloat64Desc(prefix(), -4.2), 8, func(b []byte) { PutFloat64Desc(b, -4.2) }},
+		{AppendComplex64Desc(prefix(), complex64(1+2i)), 8, func(b []byte) { PutComplex64Desc(b, complex64(1+2i)) }},
+		{AppendComplex128Desc(prefix(), 1+2i), 16, func(b []byte) { PutComplex128Desc(b, 1+2i) }},
+		{AppendByteDesc(prefix(), byte(42)), 1, func(b []byte) { PutByteDesc(b, byte(42)) }},
+		{AppendRuneDesc(prefix(), 'x'), 4, func(b []byte) { PutRuneDesc(b, 'x') }},
+		{AppendUintDesc(prefix(), uint(42)), 8, func(b []byte) { PutUintDesc(b, uint(42)) }},
+		{AppendIntDesc(prefix(), -42), 8, func(b []byte) { PutIntDesc(b, -42) }},
+		{AppendStringDesc(prefix(), s), len(s) + 1, func(b []byte) { PutStringDesc(b, s) }},
+		{AppendEscapedString(prefix(), s), EscapedStringSize(s), func(b []byte) { PutEscapedString(b, s) }},
+		{AppendEscapedStringDesc(prefix(), s), EscapedStringSize(s), func(b []byte) { PutEscapedStringDesc(b, s) }},
+		{AppendEscapedBytes(prefix(), bs), EscapedBytesSize(bs), func(b []byte) { PutEscapedBytes(b, bs) }},
+		{AppendEscapedBytesDesc(prefix(), bs), EscapedBytesSize(bs), func(b []byte) { PutEscapedBytesDesc(b, bs) }},
+		{AppendUvarint(prefix(), uint64(300)), UvarintSize(300), func(b []byte) { PutUvarint(b, uint64(300)) }},
+		{AppendVarint(prefix(), int64(-300)), VarintSize(-300), func(b []byte) { PutVarint(b, int64(-300)) }},
+		{AppendNumberInt64(prefix(), int64(-300)), NumberInt64Size(-300), func(b []byte) { PutNumberInt64(b, int64(-300)) }},
+		{AppendNumberUint64(prefix(), uint64(300)), NumberUint64Size(300), func(b []byte) { PutNumberUint64(b, uint64(300)) }},
+		{AppendNumberFloat64(prefix(), -4.2), NumberFloat64Size(-4.2), func(b []byte) { PutNumberFloat64(b, -4.2) }},
+		{AppendBigInt(prefix(), x), BigIntSize(x), func(b []byte) { PutBigInt(b, x) }},
+		{AppendDecimal(prefix(), d), DecimalSize(d), func(b []byte) { PutDecimal(b, d) }},
+		{AppendMemcomparableBytes(prefix(), bs), MemcomparableBytesSize(bs), func(b []byte) { PutMemcomparableBytes(b, bs) }},
+		{AppendMemcomparableBytesDesc(prefix(), bs), MemcomparableBytesSize(bs), func(b []byte) { PutMemcomparableBytesDesc(b, bs) }},
+		{AppendMemcomparableFloat64(prefix(), -4.2), 8, func(b []byte) { PutMemcomparableFloat64(b, -4.2) }},
+		{AppendTime(prefix(), tm, time.Millisecond), TimeSize(time.Millisecond), func(b []byte) { PutTime(b, tm, time.Millisecond) }},
+	} {
+		expected := make([]byte, 1+c.n)
+		expected[0] = 0xAB
+		c.put(expected[1:])
+		assert.Equal(t, expected, c.actual, "case %d", i)
+	}
+}
+
+func TestAppend_reuse(t *testing.T) {
+	b := make([]byte, 0, 16)
+	b = AppendInt64(b, -1)
+	b = AppendString(b, "abc")
+	assert.Equal(t, 12, len(b))
+	assert.Equal(t, 16, cap(b))
+
+	expected := make([]byte, 12)
+	PutInt64(expected, -1)
+	PutString(expected[8:], "abc")
+	assert.Equal(t, expected, b)
+
+	b = AppendUint64(b[:12], 1)
+	assert.Equal(t, 20, len(b))
+	assert.Equal(t, expected, b[:12])
+}
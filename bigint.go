@@ -0,0 +1,65 @@
+package lex
+
+import (
+	"math/big"
+)
+
+//Each big.Int is encoded as a sign marker, followed by the length and bytes of its magnitude.
+//Negative values invert both the length and the magnitude, so that larger magnitudes sort first.
+const (
+	bigNeg  = 0x00
+	bigZero = 0x01
+	bigPos  = 0x02
+)
+
+//BigIntSize returns the number of bytes PutBigInt would generate to encode v.
+func BigIntSize(v *big.Int) int {
+	if v.Sign() == 0 {
+		return 1
+	}
+	return 5 + (v.BitLen()+7)/8
+}
+
+//PutBigInt serializes *big.Int as BigIntSize(v) bytes.
+//Order is preserved for values of any magnitude by encoding a sign marker,
+//followed by the number of bytes in the magnitude as per PutUint32, and the big-endian magnitude itself.
+//For negative values, the length and magnitude are inverted.
+func PutBigInt(b []byte, v *big.Int) {
+	switch v.Sign() {
+	case 0:
+		b[0] = bigZero
+	case 1:
+		n := (v.BitLen() + 7) / 8
+		b[0] = bigPos
+		PutUint32(b[1:5], uint32(n))
+		v.FillBytes(b[5 : 5+n])
+	case -1:
+		n := (v.BitLen() + 7) / 8
+		b[0] = bigNeg
+		PutUint32(b[1:5], ^uint32(n))
+		v.FillBytes(b[5 : 5+n])
+		invert(b[5 : 5+n])
+	}
+}
+
+//BigInt deserializes *big.Int from byte slice.
+//Assumes that other values may be stored after the encoded value; the number of bytes read is BigIntSize of the result.
+//If b does not begin with a valid sign marker, BigInt returns nil.
+func BigInt(b []byte) *big.Int {
+	switch b[0] {
+	case bigZero:
+		return new(big.Int)
+	case bigPos:
+		n := Uint32(b[1:5])
+		return new(big.Int).SetBytes(b[5 : 5+n])
+	case bigNeg:
+		n := ^Uint32(b[1:5])
+		m := make([]byte, n)
+		for i := range m {
+			m[i] = ^b[5+i]
+		}
+		v := new(big.Int).SetBytes(m)
+		return v.Neg(v)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package lex
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+	"testing/quick"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func bigint(s string) *big.Int {
+	v, ok := new(big.Int).SetString(s, 0)
+	if !ok {
+		panic(s)
+	}
+	return v
+}
+
+func TestBigInt(t *testing.T) {
+	r := []*big.Int{
+		bigint("-0x10000000000000000000000000000000000"),
+		bigint("-0xFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF"),
+		bigint("-0x100"),
+		bigint("-0xFF"),
+		bigint("-2"),
+		bigint("-1"),
+		bigint("0"),
+		bigint("1"),
+		bigint("2"),
+		bigint("0xFF"),
+		bigint("0x100"),
+		bigint("0xFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF"),
+		bigint("0x10000000000000000000000000000000000"),
+	}
+	var prev []byte
+	for _, v := range r {
+		b := make([]byte, BigIntSize(v))
+		PutBigInt(b, v)
+
+		v1 := BigInt(b)
+		assert.Equal(t, 0, v.Cmp(v1), "%v", v)
+
+		if prev != nil {
+			assert.Equal(t, -1, bytes.Compare(prev, b), "%v", v)
+		}
+		prev = b
+	}
+}
+
+func TestBigInt_format(t *testing.T) {
+	b := make([]byte, BigIntSize(big.NewInt(0)))
+	PutBigInt(b, big.NewInt(0))
+	assert.Equal(t, []byte{0x01}, b)
+
+	b = make([]byte, BigIntSize(big.NewInt(258)))
+	PutBigInt(b, big.NewInt(258))
+	assert.Equal(t, []byte{0x02, 0x00, 0x00, 0x00, 0x02, 0x01, 0x02}, b)
+
+	b = make([]byte, BigIntSize(big.NewInt(-258)))
+	PutBigInt(b, big.NewInt(-258))
+	assert.Equal(t, []byte{0x00, 0xFF, 0xFF, 0xFF, 0xFD, 0xFE, 0xFD}, b)
+}
+
+func TestBigInt_RandomCompare(t *testing.T) {
+	f := func(m1, m2 []byte, n1, n2 bool) bool {
+		a1, a2 := new(big.Int).SetBytes(m1), new(big.Int).SetBytes(m2)
+		if n1 {
+			a1.Neg(a1)
+		}
+		if n2 {
+			a2.Neg(a2)
+		}
+
+		b1 := make([]byte, BigIntSize(a1)+1)
+		PutBigInt(b1, a1)
+		b1[len(b1)-1] = 0xFF //trailing data must not affect order
+
+		b2 := make([]byte, BigIntSize(a2))
+		PutBigInt(b2, a2)
+
+		expected := a1.Cmp(a2)
+		if expected == 0 {
+			expected = 1
+		}
+		return bytes.Compare(b1, b2) == expected && BigInt(b1).Cmp(a1) == 0
+	}
+	assert.Nil(t, quick.Check(f, nil))
+}
+
+func TestBigInt_invalid(t *testing.T) {
+	assert.Nil(t, BigInt([]byte{0x03}))
+}
+
+func TestBigInt_ZeroAllocs(t *testing.T) {
+	v := bigint("0xFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF")
+	b := make([]byte, BigIntSize(v))
+	assert.Zero(t, testing.AllocsPerRun(1, func() { PutBigInt(b, v) }))
+}
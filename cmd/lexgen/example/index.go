@@ -0,0 +1,79 @@
+//Package example demonstrates the methods generated by lexgen, which are checked against the reflection path by the generated test.
+package example
+
+import (
+	"time"
+
+	"github.com/xcdb/lex"
+)
+
+//go:generate go run github.com/xcdb/lex/cmd/lexgen -type=Index,Span,Event -test
+
+type tenant uint16
+
+//Index is an example key, using each of the encodings supported by lexgen.
+type Index struct {
+	Tenant  tenant
+	Name    string `lex:"desc"`
+	Path    []byte
+	Created time.Time `lex:"desc"`
+	Span
+	Score   float64 `lex:"memcomparable"`
+	Version int32   `lex:"varint"`
+	Size    uint64  `lex:"number,desc"`
+	Count   int     `lex:"number"`
+	Price   lex.Decimal
+	Label   string `lex:"escaped,nullslast"`
+	Flag    bool   `lex:"pos=0"`
+	cache   int    `lex:"-"`
+	seq     int8
+	Tag     string  `lex:"memcomparable,desc"`
+	Ratio   float32 `lex:"desc"`
+	Phase   complex64
+	Raw     []byte `lex:"memcomparable"`
+	Small   uint8  `lex:"varint,desc"`
+	Prio    prio   `lex:"desc"`
+}
+
+//prio is a priority label, such as "P1", with a custom encoding such that shorter labels sort first, so that "P2" sorts before "P10".
+type prio string
+
+//MarshalLex appends the length of p, then p itself.
+func (p prio) MarshalLex(dst []byte) []byte {
+	dst = lex.AppendUvarint(dst, uint64(len(p)))
+	return append(dst, p...)
+}
+
+//LexSize returns the number of bytes MarshalLex appends.
+func (p prio) LexSize() int {
+	return lex.UvarintSize(uint64(len(p))) + len(p)
+}
+
+//UnmarshalLex reads p from the start of b.
+func (p *prio) UnmarshalLex(b []byte) (int, error) {
+	l, n, err := lex.ReadUvarint(b)
+	if err != nil {
+		return 0, err
+	}
+	if uint64(len(b)-n) < l {
+		return 0, lex.ErrShortBuffer
+	}
+	*p = prio(b[n : n+int(l)])
+	return n + int(l), nil
+}
+
+//Span is a range of offsets, encoded as part of Index.
+type Span struct {
+	Start, End int64
+}
+
+//Event is an example key in descending order, which is decoded without allocating,
+//as each field is either of fixed size or read in place.
+type Event struct {
+	When   time.Time `lex:"desc"`
+	Seq    int64     `lex:"varint,desc"`
+	Amount int       `lex:"number,desc"`
+	Weight float64   `lex:"memcomparable,desc"`
+	Window Span      `lex:"desc"`
+	Prio   prio      `lex:"desc"`
+}
@@ -0,0 +1,532 @@
+// Code generated by "lexgen -type=Index,Span,Event"; DO NOT EDIT.
+
+package example
+
+import (
+	"errors"
+	"time"
+
+	"github.com/xcdb/lex"
+)
+
+// KeySize returns the number of bytes AppendKey appends to encode v, as per lex.Size.
+func (v Index) KeySize() int {
+	return 1 + 2 + len(v.Name) + 1 + lex.EscapedBytesSize(v.Path) + lex.TimeSize(time.Nanosecond) + v.Span.KeySize() + 8 + lex.VarintSize(int64(v.Version)) + lex.NumberUint64Size(uint64(v.Size)) + lex.NumberInt64Size(int64(v.Count)) + lex.DecimalSize(v.Price) + lex.EscapedStringSize(v.Label) + 1 + (len(v.Tag)/8+1)*9 + 4 + 8 + lex.MemcomparableBytesSize(v.Raw) + lex.UvarintSize(uint64(v.Small)) + v.Prio.LexSize()
+}
+
+// AppendKey appends the encoding of v to b, as per lex.PutReflect, and returns the extended slice.
+func (v Index) AppendKey(b []byte) []byte {
+	n := len(b)
+	b = append(b, make([]byte, v.KeySize())...)
+
+	lex.PutBool(b[n:], v.Flag)
+	n += 1
+
+	lex.PutUint16(b[n:], uint16(v.Tenant))
+	n += 2
+
+	lex.PutStringDesc(b[n:], v.Name)
+	n += len(v.Name) + 1
+
+	lex.PutEscapedBytes(b[n:], v.Path)
+	n += lex.EscapedBytesSize(v.Path)
+
+	{
+		m := n
+		lex.PutTime(b[n:], v.Created, time.Nanosecond)
+		n += lex.TimeSize(time.Nanosecond)
+		for i := m; i < n; i++ {
+			b[i] = ^b[i]
+		}
+	}
+
+	n = len(v.Span.AppendKey(b[:n]))
+
+	lex.PutMemcomparableFloat64(b[n:], float64(v.Score))
+	n += 8
+
+	n += lex.PutVarint(b[n:], int64(v.Version))
+
+	{
+		m := n
+		n += lex.PutNumberUint64(b[n:], uint64(v.Size))
+		for i := m; i < n; i++ {
+			b[i] = ^b[i]
+		}
+	}
+
+	n += lex.PutNumberInt64(b[n:], int64(v.Count))
+
+	lex.PutDecimal(b[n:], v.Price)
+	n += lex.DecimalSize(v.Price)
+
+	b[n] = 0x01
+	n++
+	lex.PutEscapedString(b[n:], v.Label)
+	n += lex.EscapedStringSize(v.Label)
+
+	lex.PutMemcomparableBytesDesc(b[n:], []byte(v.Tag))
+	n += (len(v.Tag)/8 + 1) * 9
+
+	lex.PutFloat32Desc(b[n:], v.Ratio)
+	n += 4
+
+	lex.PutComplex64(b[n:], v.Phase)
+	n += 8
+
+	lex.PutMemcomparableBytes(b[n:], v.Raw)
+	n += lex.MemcomparableBytesSize(v.Raw)
+
+	{
+		m := n
+		n += lex.PutUvarint(b[n:], uint64(v.Small))
+		for i := m; i < n; i++ {
+			b[i] = ^b[i]
+		}
+	}
+
+	{
+		m := n
+		s := v.Prio.LexSize()
+		e := v.Prio.MarshalLex(b[n : n : n+s])
+		if len(e) != s {
+			panic(errors.New("Index.AppendKey: Prio: MarshalLex does not match its size"))
+		}
+		n += copy(b[n:], e)
+		for i := m; i < n; i++ {
+			b[i] = ^b[i]
+		}
+	}
+	return b
+}
+
+// DecodeKey decodes v from the start of b, as per lex.Reflect, returning the number of bytes read.
+func (v *Index) DecodeKey(b []byte) (int, error) {
+	n := 0
+
+	{
+		x, m, err := lex.ReadBool(b[n:])
+		if err != nil {
+			return 0, err
+		}
+		v.Flag = x
+		n += m
+	}
+
+	{
+		x, m, err := lex.ReadUint16(b[n:])
+		if err != nil {
+			return 0, err
+		}
+		v.Tenant = tenant(x)
+		n += m
+	}
+
+	{
+		x, m, err := lex.ReadStringDesc(b[n:])
+		if err != nil {
+			return 0, err
+		}
+		v.Name = x
+		n += m
+	}
+
+	{
+		x, m, err := lex.ReadEscapedBytes(b[n:])
+		if err != nil {
+			return 0, err
+		}
+		v.Path = x
+		n += m
+	}
+
+	{
+		var a [12]byte
+		s := a[:copy(a[:], b[n:])]
+		for i := range s {
+			s[i] = ^s[i]
+		}
+		x, m, err := lex.ReadTime(s, time.Nanosecond)
+		if err != nil {
+			return 0, err
+		}
+		v.Created = x
+		n += m
+	}
+
+	{
+		m, err := v.Span.DecodeKey(b[n:])
+		if err != nil {
+			return 0, err
+		}
+		n += m
+	}
+
+	{
+		x, m, err := lex.ReadMemcomparableFloat64(b[n:])
+		if err != nil {
+			return 0, err
+		}
+		v.Score = x
+		n += m
+	}
+
+	{
+		x, m, err := lex.ReadVarint(b[n:])
+		if err != nil {
+			return 0, err
+		}
+		if int64(int32(x)) != x {
+			return 0, lex.ErrInvalid
+		}
+		v.Version = int32(x)
+		n += m
+	}
+
+	{
+		x, m, err := lex.ReadNumberDesc(b[n:])
+		if err != nil {
+			return 0, err
+		}
+		var u uint64
+		switch x := x.(type) {
+		case int64:
+			if x < 0 {
+				return 0, lex.ErrInvalid
+			}
+			u = uint64(x)
+		case uint64:
+			u = x
+		default:
+			return 0, lex.ErrInvalid
+		}
+		v.Size = u
+		n += m
+	}
+
+	{
+		x, m, err := lex.ReadNumber(b[n:])
+		if err != nil {
+			return 0, err
+		}
+		i, ok := x.(int64)
+		if !ok || int64(int(i)) != i {
+			return 0, lex.ErrInvalid
+		}
+		v.Count = int(i)
+		n += m
+	}
+
+	{
+		x, m, err := lex.ReadDecimal(b[n:])
+		if err != nil {
+			return 0, err
+		}
+		v.Price = x
+		n += m
+	}
+
+	if len(b) <= n {
+		return 0, lex.ErrShortBuffer
+	}
+	if b[n] != 0x01 {
+		return 0, lex.ErrInvalid
+	}
+	n++
+	{
+		x, m, err := lex.ReadEscapedString(b[n:])
+		if err != nil {
+			return 0, err
+		}
+		v.Label = x
+		n += m
+	}
+
+	{
+		x, m, err := lex.ReadMemcomparableBytesDesc(b[n:])
+		if err != nil {
+			return 0, err
+		}
+		v.Tag = string(x)
+		n += m
+	}
+
+	{
+		x, m, err := lex.ReadFloat32Desc(b[n:])
+		if err != nil {
+			return 0, err
+		}
+		v.Ratio = x
+		n += m
+	}
+
+	{
+		x, m, err := lex.ReadComplex64(b[n:])
+		if err != nil {
+			return 0, err
+		}
+		v.Phase = x
+		n += m
+	}
+
+	{
+		x, m, err := lex.ReadMemcomparableBytes(b[n:])
+		if err != nil {
+			return 0, err
+		}
+		v.Raw = x
+		n += m
+	}
+
+	{
+		var a [9]byte
+		s := a[:copy(a[:], b[n:])]
+		for i := range s {
+			s[i] = ^s[i]
+		}
+		x, m, err := lex.ReadUvarint(s)
+		if err != nil {
+			return 0, err
+		}
+		if uint64(uint8(x)) != x {
+			return 0, lex.ErrInvalid
+		}
+		v.Small = uint8(x)
+		n += m
+	}
+
+	{
+		var a [64]byte
+		s := append(a[:0], b[n:]...)
+		for i := range s {
+			s[i] = ^s[i]
+		}
+		m, err := v.Prio.UnmarshalLex(s)
+		if err != nil {
+			return 0, err
+		}
+		if m < 0 || m > len(s) {
+			return 0, lex.ErrInvalid
+		}
+		n += m
+	}
+	return n, nil
+}
+
+// KeySize returns the number of bytes AppendKey appends to encode v, as per lex.Size.
+func (v Span) KeySize() int {
+	return 8 + 8
+}
+
+// AppendKey appends the encoding of v to b, as per lex.PutReflect, and returns the extended slice.
+func (v Span) AppendKey(b []byte) []byte {
+	n := len(b)
+	b = append(b, make([]byte, v.KeySize())...)
+
+	lex.PutInt64(b[n:], v.Start)
+	n += 8
+
+	lex.PutInt64(b[n:], v.End)
+	n += 8
+	return b
+}
+
+// DecodeKey decodes v from the start of b, as per lex.Reflect, returning the number of bytes read.
+func (v *Span) DecodeKey(b []byte) (int, error) {
+	n := 0
+
+	{
+		x, m, err := lex.ReadInt64(b[n:])
+		if err != nil {
+			return 0, err
+		}
+		v.Start = x
+		n += m
+	}
+
+	{
+		x, m, err := lex.ReadInt64(b[n:])
+		if err != nil {
+			return 0, err
+		}
+		v.End = x
+		n += m
+	}
+	return n, nil
+}
+
+// decodeKeyDesc decodes v from the start of b, as per DecodeKey, with each byte of b inverted as it is read.
+func (v *Span) decodeKeyDesc(b []byte) (int, error) {
+	n := 0
+
+	{
+		x, m, err := lex.ReadInt64Desc(b[n:])
+		if err != nil {
+			return 0, err
+		}
+		v.Start = x
+		n += m
+	}
+
+	{
+		x, m, err := lex.ReadInt64Desc(b[n:])
+		if err != nil {
+			return 0, err
+		}
+		v.End = x
+		n += m
+	}
+	return n, nil
+}
+
+// KeySize returns the number of bytes AppendKey appends to encode v, as per lex.Size.
+func (v Event) KeySize() int {
+	return lex.TimeSize(time.Nanosecond) + lex.VarintSize(int64(v.Seq)) + lex.NumberInt64Size(int64(v.Amount)) + 8 + v.Window.KeySize() + v.Prio.LexSize()
+}
+
+// AppendKey appends the encoding of v to b, as per lex.PutReflect, and returns the extended slice.
+func (v Event) AppendKey(b []byte) []byte {
+	n := len(b)
+	b = append(b, make([]byte, v.KeySize())...)
+
+	{
+		m := n
+		lex.PutTime(b[n:], v.When, time.Nanosecond)
+		n += lex.TimeSize(time.Nanosecond)
+		for i := m; i < n; i++ {
+			b[i] = ^b[i]
+		}
+	}
+
+	{
+		m := n
+		n += lex.PutVarint(b[n:], int64(v.Seq))
+		for i := m; i < n; i++ {
+			b[i] = ^b[i]
+		}
+	}
+
+	{
+		m := n
+		n += lex.PutNumberInt64(b[n:], int64(v.Amount))
+		for i := m; i < n; i++ {
+			b[i] = ^b[i]
+		}
+	}
+
+	{
+		m := n
+		lex.PutMemcomparableFloat64(b[n:], float64(v.Weight))
+		n += 8
+		for i := m; i < n; i++ {
+			b[i] = ^b[i]
+		}
+	}
+
+	{
+		m := n
+		n = len(v.Window.AppendKey(b[:n]))
+		for i := m; i < n; i++ {
+			b[i] = ^b[i]
+		}
+	}
+
+	{
+		m := n
+		s := v.Prio.LexSize()
+		e := v.Prio.MarshalLex(b[n : n : n+s])
+		if len(e) != s {
+			panic(errors.New("Event.AppendKey: Prio: MarshalLex does not match its size"))
+		}
+		n += copy(b[n:], e)
+		for i := m; i < n; i++ {
+			b[i] = ^b[i]
+		}
+	}
+	return b
+}
+
+// DecodeKey decodes v from the start of b, as per lex.Reflect, returning the number of bytes read.
+func (v *Event) DecodeKey(b []byte) (int, error) {
+	n := 0
+
+	{
+		var a [12]byte
+		s := a[:copy(a[:], b[n:])]
+		for i := range s {
+			s[i] = ^s[i]
+		}
+		x, m, err := lex.ReadTime(s, time.Nanosecond)
+		if err != nil {
+			return 0, err
+		}
+		v.When = x
+		n += m
+	}
+
+	{
+		var a [9]byte
+		s := a[:copy(a[:], b[n:])]
+		for i := range s {
+			s[i] = ^s[i]
+		}
+		x, m, err := lex.ReadVarint(s)
+		if err != nil {
+			return 0, err
+		}
+		v.Seq = x
+		n += m
+	}
+
+	{
+		x, m, err := lex.ReadNumberDesc(b[n:])
+		if err != nil {
+			return 0, err
+		}
+		i, ok := x.(int64)
+		if !ok || int64(int(i)) != i {
+			return 0, lex.ErrInvalid
+		}
+		v.Amount = int(i)
+		n += m
+	}
+
+	{
+		var a [8]byte
+		s := a[:copy(a[:], b[n:])]
+		for i := range s {
+			s[i] = ^s[i]
+		}
+		x, m, err := lex.ReadMemcomparableFloat64(s)
+		if err != nil {
+			return 0, err
+		}
+		v.Weight = x
+		n += m
+	}
+
+	{
+		m, err := v.Window.decodeKeyDesc(b[n:])
+		if err != nil {
+			return 0, err
+		}
+		n += m
+	}
+
+	{
+		var a [64]byte
+		s := append(a[:0], b[n:]...)
+		for i := range s {
+			s[i] = ^s[i]
+		}
+		m, err := v.Prio.UnmarshalLex(s)
+		if err != nil {
+			return 0, err
+		}
+		if m < 0 || m > len(s) {
+			return 0, lex.ErrInvalid
+		}
+		n += m
+	}
+	return n, nil
+}
@@ -0,0 +1,184 @@
+// Code generated by "lexgen -type=Index,Span,Event"; DO NOT EDIT.
+
+package example
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/xcdb/lex"
+)
+
+func TestIndex_lexgen(t *testing.T) {
+	for _, v := range []Index{
+		{},
+		{
+			Flag:    true,
+			Tenant:  tenant(2),
+			Name:    "a",
+			Path:    []byte("a\x00b"),
+			Created: time.Unix(1, 2),
+			Score:   -1.5,
+			Version: -2,
+			Size:    2,
+			Count:   -2,
+			Label:   "a\x00b",
+			Tag:     "a\x00b",
+			Ratio:   -1.5,
+			Phase:   1 + 2i,
+			Raw:     []byte("a\x00b"),
+			Small:   2,
+			Prio:    prio("a"),
+		},
+		{
+			Flag:    false,
+			Tenant:  tenant(3),
+			Name:    "abcdefghij",
+			Path:    []byte("abcdefghij"),
+			Created: time.Unix(-1, 0),
+			Score:   2.25,
+			Version: 3,
+			Size:    3,
+			Count:   3,
+			Label:   "abcdefghij",
+			Tag:     "abcdefghij",
+			Ratio:   2.25,
+			Phase:   -3i,
+			Raw:     []byte("abcdefghij"),
+			Small:   3,
+			Prio:    prio("abcdefghij"),
+		},
+	} {
+		k, err := lex.Key(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := v.KeySize(); n != len(k) {
+			t.Errorf("%+v: KeySize() = %d, want %d", v, n, len(k))
+		}
+		if b := v.AppendKey([]byte{0xFF}); b[0] != 0xFF || !bytes.Equal(b[1:], k) {
+			t.Errorf("%+v: AppendKey() = %x, want %x", v, b[1:], k)
+		}
+
+		var got, want Index
+		n, err := got.DecodeKey(k)
+		if err != nil || n != len(k) {
+			t.Errorf("%x: DecodeKey() = %d, %v, want %d, nil", k, n, err, len(k))
+		}
+		if err := lex.Reflect(k, &want); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%x: DecodeKey() = %+v, want %+v", k, got, want)
+		}
+
+		// truncated keys are rejected with the same error as lex.Reflect
+		for i := 0; i < len(k); i++ {
+			var got, want Index
+			_, err := got.DecodeKey(k[:i])
+			if werr := lex.Reflect(k[:i], &want); err != werr {
+				t.Errorf("%x: DecodeKey() error = %v, want %v", k[:i], err, werr)
+			}
+		}
+	}
+}
+
+func TestSpan_lexgen(t *testing.T) {
+	for _, v := range []Span{
+		{},
+		{
+			Start: -2,
+			End:   -2,
+		},
+		{
+			Start: 3,
+			End:   3,
+		},
+	} {
+		k, err := lex.Key(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := v.KeySize(); n != len(k) {
+			t.Errorf("%+v: KeySize() = %d, want %d", v, n, len(k))
+		}
+		if b := v.AppendKey([]byte{0xFF}); b[0] != 0xFF || !bytes.Equal(b[1:], k) {
+			t.Errorf("%+v: AppendKey() = %x, want %x", v, b[1:], k)
+		}
+
+		var got, want Span
+		n, err := got.DecodeKey(k)
+		if err != nil || n != len(k) {
+			t.Errorf("%x: DecodeKey() = %d, %v, want %d, nil", k, n, err, len(k))
+		}
+		if err := lex.Reflect(k, &want); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%x: DecodeKey() = %+v, want %+v", k, got, want)
+		}
+
+		// truncated keys are rejected with the same error as lex.Reflect
+		for i := 0; i < len(k); i++ {
+			var got, want Span
+			_, err := got.DecodeKey(k[:i])
+			if werr := lex.Reflect(k[:i], &want); err != werr {
+				t.Errorf("%x: DecodeKey() error = %v, want %v", k[:i], err, werr)
+			}
+		}
+	}
+}
+
+func TestEvent_lexgen(t *testing.T) {
+	for _, v := range []Event{
+		{},
+		{
+			When:   time.Unix(1, 2),
+			Seq:    -2,
+			Amount: -2,
+			Weight: -1.5,
+			Prio:   prio("a"),
+		},
+		{
+			When:   time.Unix(-1, 0),
+			Seq:    3,
+			Amount: 3,
+			Weight: 2.25,
+			Prio:   prio("abcdefghij"),
+		},
+	} {
+		k, err := lex.Key(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := v.KeySize(); n != len(k) {
+			t.Errorf("%+v: KeySize() = %d, want %d", v, n, len(k))
+		}
+		if b := v.AppendKey([]byte{0xFF}); b[0] != 0xFF || !bytes.Equal(b[1:], k) {
+			t.Errorf("%+v: AppendKey() = %x, want %x", v, b[1:], k)
+		}
+
+		var got, want Event
+		n, err := got.DecodeKey(k)
+		if err != nil || n != len(k) {
+			t.Errorf("%x: DecodeKey() = %d, %v, want %d, nil", k, n, err, len(k))
+		}
+		if err := lex.Reflect(k, &want); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%x: DecodeKey() = %+v, want %+v", k, got, want)
+		}
+
+		// truncated keys are rejected with the same error as lex.Reflect
+		for i := 0; i < len(k); i++ {
+			var got, want Event
+			_, err := got.DecodeKey(k[:i])
+			if werr := lex.Reflect(k[:i], &want); err != werr {
+				t.Errorf("%x: DecodeKey() error = %v, want %v", k[:i], err, werr)
+			}
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package example
+
+import (
+	"testing"
+	"time"
+
+	"github.com/xcdb/lex"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvent_allocs(t *testing.T) {
+	v := Event{time.Unix(1, 2), -300, 7, 2.5, Span{1, 2}, ""}
+	k := lex.MustKey(v)
+
+	var got Event
+	allocs := testing.AllocsPerRun(100, func() {
+		if _, err := got.DecodeKey(k); err != nil {
+			t.Fatal(err)
+		}
+	})
+	assert.Equal(t, 0.0, allocs)
+}
+
+func TestEvent_invalidTime(t *testing.T) {
+	k := lex.MustKey(Event{When: time.Unix(1, 2)})
+	copy(k[8:12], []byte{0, 0, 0, 0}) //inverted, a nanosecond field beyond a second
+
+	var got, want Event
+	_, err := got.DecodeKey(k)
+	assert.Equal(t, lex.ErrInvalid, err)
+	assert.Equal(t, lex.ErrInvalid, lex.Reflect(k, &want))
+}
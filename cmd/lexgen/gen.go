@@ -0,0 +1,1008 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const lexPath = "github.com/xcdb/lex"
+
+// decl is a type declared in the package, with the file declaring it.
+type decl struct {
+	spec *ast.TypeSpec
+	file *ast.File
+}
+
+// generator holds the type declarations of a package, and accumulates generated source.
+// Methods maps each type name to the names of its methods, and whether each has a pointer receiver.
+type generator struct {
+	pkg     string
+	decls   map[string]decl
+	methods map[string]map[string]bool
+	types   map[string]bool
+	imports map[string]bool
+	buf     bytes.Buffer
+}
+
+// load parses the non-test Go files in dir.
+func load(dir string) (*generator, error) {
+	names, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+
+	g := &generator{decls: make(map[string]decl), methods: make(map[string]map[string]bool)}
+	fset := token.NewFileSet()
+	for _, name := range names {
+		if strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, name, nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		if g.pkg == "" {
+			g.pkg = f.Name.Name
+		} else if f.Name.Name != g.pkg {
+			return nil, fmt.Errorf("multiple packages in %s", dir)
+		}
+		for _, d := range f.Decls {
+			if fd, ok := d.(*ast.FuncDecl); ok {
+				g.addMethod(fd)
+				continue
+			}
+			gd, ok := d.(*ast.GenDecl)
+			if !ok || gd.Tok != token.TYPE {
+				continue
+			}
+			for _, s := range gd.Specs {
+				ts := s.(*ast.TypeSpec)
+				g.decls[ts.Name.Name] = decl{ts, f}
+			}
+		}
+	}
+	if g.pkg == "" {
+		return nil, fmt.Errorf("no Go files in %s", dir)
+	}
+	return g, nil
+}
+
+// addMethod records fd if it is a method.
+func (g *generator) addMethod(fd *ast.FuncDecl) {
+	if fd.Recv == nil || len(fd.Recv.List) != 1 {
+		return
+	}
+	t, ptr := fd.Recv.List[0].Type, false
+	if s, ok := t.(*ast.StarExpr); ok {
+		t, ptr = s.X, true
+	}
+	id, ok := t.(*ast.Ident)
+	if !ok {
+		return
+	}
+	if g.methods[id.Name] == nil {
+		g.methods[id.Name] = make(map[string]bool)
+	}
+	g.methods[id.Name][fd.Name.Name] = ptr
+}
+
+// marshaler reports whether type e implements both lex.Marshaler and lex.Unmarshaler, such that lex.PutReflect and lex.Reflect use them,
+// and whether it also implements lex.Sizer. If e implements only one of them, marshaler returns an error.
+func (g *generator) marshaler(e ast.Expr) (ok, sizer bool, err error) {
+	id, isIdent := e.(*ast.Ident)
+	if !isIdent {
+		return false, false, nil
+	}
+	ms := g.methods[id.Name]
+	ptr, marshal := ms["MarshalLex"]
+	_, unmarshal := ms["UnmarshalLex"]
+	switch {
+	case !marshal && !unmarshal:
+		return false, false, nil
+	case !unmarshal:
+		return false, false, fmt.Errorf("type %s implements lex.Marshaler but not lex.Unmarshaler", id.Name)
+	case !marshal:
+		return false, false, fmt.Errorf("type %s implements lex.Unmarshaler but not lex.Marshaler", id.Name)
+	case ptr:
+		return false, false, fmt.Errorf("method %s.MarshalLex must have a value receiver", id.Name)
+	}
+	ptr, sizer = ms["LexSize"]
+	return true, sizer && !ptr, nil
+}
+
+// field is a struct field selected for encoding, with the options selected by its lex tag, as per lex.PutReflect.
+type field struct {
+	name string //selector, as in v.name
+	typ  string //declared type
+	kind string //basic type, or one of bytes, time, decimal and struct
+
+	pos                                                    int //position selected by the tag, or -1
+	desc, nullable, escaped, varint, number, memcomparable bool
+	marshaler, sizer                                       bool //encoded by its MarshalLex method, and sized by LexSize
+}
+
+// fields returns the exported fields of struct type name in encoding order, as per lex.PutReflect.
+func (g *generator) fields(name string) ([]field, error) {
+	d, ok := g.decls[name]
+	if !ok {
+		return nil, fmt.Errorf("type %s not found", name)
+	}
+	st, ok := d.spec.Type.(*ast.StructType)
+	if !ok {
+		return nil, fmt.Errorf("type %s is not a struct", name)
+	}
+
+	var fs []field
+	for _, af := range st.Fields.List {
+		var tag string
+		var tagged bool
+		if af.Tag != nil {
+			s, _ := strconv.Unquote(af.Tag.Value)
+			tag, tagged = reflect.StructTag(s).Lookup("lex")
+		}
+
+		for _, n := range fieldNames(af) {
+			f := field{name: n, typ: types.ExprString(af.Type), pos: -1}
+			if tag == "-" {
+				continue
+			}
+			if !ast.IsExported(n) {
+				if tagged {
+					return nil, fmt.Errorf("%s.%s: unexported field cannot be tagged %q", name, n, tag)
+				}
+				continue
+			}
+
+			for _, s := range strings.Split(tag, ",") {
+				switch {
+				case s == "" && tag == "":
+				case s == "desc":
+					f.desc = true
+				case s == "nullsfirst", s == "nullslast":
+					f.nullable = true
+				case s == "escaped":
+					f.escaped = true
+				case s == "varint":
+					f.varint = true
+				case s == "number":
+					f.number = true
+				case s == "memcomparable":
+					f.memcomparable = true
+				case strings.HasPrefix(s, "pos="):
+					p, err := strconv.Atoi(s[len("pos="):])
+					if err != nil || p < 0 || f.pos >= 0 {
+						return nil, fmt.Errorf("%s.%s: invalid tag %q", name, n, tag)
+					}
+					f.pos = p
+				default:
+					return nil, fmt.Errorf("%s.%s: unknown option %q", name, n, s)
+				}
+			}
+
+			marshaler, sizer, err := g.marshaler(af.Type)
+			if err != nil {
+				return nil, fmt.Errorf("%s.%s: %v", name, n, err)
+			}
+			if marshaler {
+				f.marshaler, f.sizer = true, sizer
+				if f.escaped || f.varint || f.number || f.memcomparable {
+					return nil, fmt.Errorf("%s.%s: invalid tag %q for lex.Marshaler", name, n, tag)
+				}
+				f.kind, _ = g.resolve(af.Type, d.file) //for sample values only
+				fs = append(fs, f)
+				continue
+			}
+
+			kind, err := g.resolve(af.Type, d.file)
+			if err != nil {
+				return nil, fmt.Errorf("%s.%s: %v", name, n, err)
+			}
+			f.kind = kind
+			if f.number && isFloat(kind) {
+				return nil, fmt.Errorf("%s.%s: number is not supported for floats", name, n)
+			}
+			if kind == "struct" && (f.escaped || f.varint || f.number || f.memcomparable) {
+				return nil, fmt.Errorf("%s.%s: invalid tag %q for struct", name, n, tag)
+			}
+			fs = append(fs, f)
+		}
+	}
+	if len(fs) == 0 {
+		return nil, fmt.Errorf("type %s has no fields to encode", name)
+	}
+	return order(name, fs)
+}
+
+// order places each field tagged with a position at that position, counting from zero,
+// with the other fields filling the remaining positions in declaration order.
+func order(name string, fs []field) ([]field, error) {
+	ordered := make([]field, len(fs))
+	placed := make([]bool, len(fs))
+	for _, f := range fs {
+		if f.pos < 0 {
+			continue
+		}
+		if f.pos >= len(fs) {
+			return nil, fmt.Errorf("%s.%s: position %d is out of range for %d fields", name, f.name, f.pos, len(fs))
+		}
+		if placed[f.pos] {
+			return nil, fmt.Errorf("%s.%s: position %d is already taken", name, f.name, f.pos)
+		}
+		ordered[f.pos], placed[f.pos] = f, true
+	}
+	i := 0
+	for _, f := range fs {
+		if f.pos >= 0 {
+			continue
+		}
+		for placed[i] {
+			i++
+		}
+		ordered[i], placed[i] = f, true
+	}
+	return ordered, nil
+}
+
+// fieldNames returns the names of af, or the type name if embedded.
+func fieldNames(af *ast.Field) []string {
+	if len(af.Names) == 0 {
+		t := af.Type
+		if s, ok := t.(*ast.StarExpr); ok {
+			t = s.X
+		}
+		if s, ok := t.(*ast.SelectorExpr); ok {
+			t = s.Sel
+		}
+		return []string{types.ExprString(t)}
+	}
+	ns := make([]string, len(af.Names))
+	for i, n := range af.Names {
+		ns[i] = n.Name
+	}
+	return ns
+}
+
+// resolve returns the kind of type e, as declared in file f.
+func (g *generator) resolve(e ast.Expr, f *ast.File) (string, error) {
+	switch e := e.(type) {
+	case *ast.Ident:
+		switch e.Name {
+		case "byte":
+			return "uint8", nil
+		case "rune":
+			return "int32", nil
+		case "bool", "string", "float32", "float64", "complex64", "complex128",
+			"int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64":
+			return e.Name, nil
+		}
+		if g.types[e.Name] {
+			return "struct", nil
+		}
+		if d, ok := g.decls[e.Name]; ok {
+			if _, ok := d.spec.Type.(*ast.StructType); ok {
+				return "", fmt.Errorf("type %s must also be generated", e.Name)
+			}
+			return g.resolve(d.spec.Type, d.file)
+		}
+	case *ast.ArrayType:
+		if k, err := g.resolve(e.Elt, f); e.Len == nil && err == nil && k == "uint8" {
+			return "bytes", nil
+		}
+	case *ast.SelectorExpr:
+		if x, ok := e.X.(*ast.Ident); ok {
+			switch importPath(f, x.Name) + "." + e.Sel.Name {
+			case "time.Time":
+				return "time", nil
+			case lexPath + ".Decimal":
+				return "decimal", nil
+			}
+		}
+	}
+	return "", fmt.Errorf("unsupported type %s", types.ExprString(e))
+}
+
+// importPath returns the path of the package imported as name by f.
+func importPath(f *ast.File, name string) string {
+	for _, imp := range f.Imports {
+		p, _ := strconv.Unquote(imp.Path.Value)
+		n := p[strings.LastIndex(p, "/")+1:]
+		if imp.Name != nil {
+			n = imp.Name.Name
+		}
+		if n == name {
+			return p
+		}
+	}
+	return ""
+}
+
+func isInt(kind string) bool {
+	switch kind {
+	case "int", "int8", "int16", "int32", "int64":
+		return true
+	}
+	return false
+}
+
+func isUint(kind string) bool {
+	switch kind {
+	case "uint", "uint8", "uint16", "uint32", "uint64":
+		return true
+	}
+	return false
+}
+
+func isFloat(kind string) bool {
+	return kind == "float32" || kind == "float64"
+}
+
+// fixed maps each fixed-size kind to the name of its lex primitives and its size.
+var fixed = map[string]struct {
+	name string
+	size int
+}{
+	"bool":       {"Bool", 1},
+	"int":        {"Int", 8},
+	"int8":       {"Int8", 1},
+	"int16":      {"Int16", 2},
+	"int32":      {"Int32", 4},
+	"int64":      {"Int64", 8},
+	"uint":       {"Uint", 8},
+	"uint8":      {"Uint8", 1},
+	"uint16":     {"Uint16", 2},
+	"uint32":     {"Uint32", 4},
+	"uint64":     {"Uint64", 8},
+	"float32":    {"Float32", 4},
+	"float64":    {"Float64", 8},
+	"complex64":  {"Complex64", 8},
+	"complex128": {"Complex128", 16},
+}
+
+// goType returns the Go type of kind, or typ for structs.
+func (f field) goType() string {
+	switch f.kind {
+	case "bytes":
+		return "[]byte"
+	case "time":
+		return "time.Time"
+	case "decimal":
+		return "lex.Decimal"
+	case "struct":
+		return f.typ
+	}
+	return f.kind
+}
+
+// val returns the field of v.
+func (f field) val() string {
+	return "v." + f.name
+}
+
+// conv returns the field of v, converted to its Go type.
+func (f field) conv() string {
+	if f.typ == f.goType() {
+		return f.val()
+	}
+	return f.goType() + "(" + f.val() + ")"
+}
+
+// assign returns a statement setting the field of v to x of kind from, converted to the declared type.
+func (f field) assign(x, from string) string {
+	if f.typ == f.goType() {
+		return f.val() + " = " + conv(f.kind, from, x)
+	}
+	return f.val() + " = " + f.typ + "(" + x + ")"
+}
+
+// conv returns x of kind from, converted to kind.
+func conv(kind, from, x string) string {
+	if kind == from {
+		return x
+	}
+	return kind + "(" + x + ")"
+}
+
+// encoding is the code generated for a field. Get decodes the field from b[n:], in place, returning any error as per lex.Reflect.
+// If scoped is set, put declares variables, so is enclosed in a block.
+type encoding struct {
+	size       string
+	put        []string
+	get        []string
+	nativeDesc bool
+	scoped     bool
+}
+
+// errInvalid is the statement returning lex.ErrInvalid from DecodeKey, as lex.Reflect does for values it cannot represent.
+const errInvalid = "return 0, lex.ErrInvalid"
+
+// checkErr are the statements returning err from DecodeKey, if set.
+var checkErr = []string{"if err != nil {", "return 0, err", "}"}
+
+// inverted returns statements declaring s as an inverted copy of at most size bytes of b[n:], held in an array on the stack.
+func inverted(size int) []string {
+	return []string{
+		fmt.Sprintf("var a [%d]byte", size),
+		"s := a[:copy(a[:], b[n:])]",
+		"for i := range s {",
+		"s[i] = ^s[i]",
+		"}",
+	}
+}
+
+// encoding returns the code generated for f, with option precedence as per lex.PutReflect.
+// If desc is set and the encoding has native descending primitives, they are used.
+// When decoding, values without such primitives are inverted as they are read, or if of fixed size, on the stack.
+// Type t is the struct type declaring f.
+func (g *generator) encoding(t string, f field) encoding {
+	d := ""
+	if f.desc {
+		d = "Desc"
+	}
+	k := f.kind
+	val := f.val()
+	if k != "struct" || f.marshaler {
+		g.imports[lexPath] = true
+	}
+
+	switch {
+	case f.marshaler:
+		//the encoding must be the size reported, else it would overrun or fall short of the space allotted by KeySize
+		g.imports["errors"] = true
+		size := fmt.Sprintf("len(%s.MarshalLex(nil))", val)
+		if f.sizer {
+			size = val + ".LexSize()"
+		}
+		e := encoding{
+			size: size,
+			put: []string{
+				"s := " + size,
+				"e := " + val + ".MarshalLex(b[n:n:n+s])",
+				"if len(e) != s {",
+				fmt.Sprintf("panic(errors.New(%q))", t+".AppendKey: "+f.name+": MarshalLex does not match its size"),
+				"}",
+				"n += copy(b[n:], e)",
+			},
+			scoped: true,
+		}
+		src, end := "b[n:]", "len(b)-n"
+		if f.desc {
+			//the extent of a custom encoding is unknown until it has been read, so the rest of b is inverted,
+			//on the stack if it is short enough
+			src, end = "s", "len(s)"
+			e.get = []string{
+				"var a [64]byte",
+				"s := append(a[:0], b[n:]...)",
+				"for i := range s {",
+				"s[i] = ^s[i]",
+				"}",
+			}
+		}
+		e.get = append(e.get, "m, err := "+val+".UnmarshalLex("+src+")")
+		e.get = append(e.get, checkErr...)
+		e.get = append(e.get, "if m < 0 || m > "+end+" {", errInvalid, "}", "n += m")
+		return e
+
+	case f.number && isInt(k):
+		get := append([]string{"x, m, err := lex.ReadNumber" + d + "(b[n:])"}, checkErr...)
+		return encoding{
+			size: fmt.Sprintf("lex.NumberInt64Size(int64(%s))", val),
+			put:  []string{fmt.Sprintf("n += lex.PutNumberInt64(b[n:], int64(%s))", val)},
+			get: append(get,
+				"i, ok := x.(int64)",
+				"if !ok"+overflow(k, "int64", "i")+" {", errInvalid, "}",
+				f.assign("i", "int64"),
+				"n += m",
+			),
+		}
+
+	case f.number && isUint(k):
+		get := append([]string{"x, m, err := lex.ReadNumber" + d + "(b[n:])"}, checkErr...)
+		get = append(get,
+			"var u uint64",
+			"switch x := x.(type) {",
+			"case int64:",
+			"if x < 0 {", errInvalid, "}",
+			"u = uint64(x)",
+			"case uint64:",
+			"u = x",
+			"default:", errInvalid,
+			"}",
+		)
+		if k != "uint64" {
+			get = append(get, "if "+overflow(k, "uint64", "u")[len(" || "):]+" {", errInvalid, "}")
+		}
+		return encoding{
+			size: fmt.Sprintf("lex.NumberUint64Size(uint64(%s))", val),
+			put:  []string{fmt.Sprintf("n += lex.PutNumberUint64(b[n:], uint64(%s))", val)},
+			get:  append(get, f.assign("u", "uint64"), "n += m"),
+		}
+
+	case f.varint && (isInt(k) || isUint(k)):
+		name, t := "Varint", "int64"
+		if isUint(k) {
+			name, t = "Uvarint", "uint64"
+		}
+		get := []string{"x, m, err := lex.Read" + name + "(b[n:])"}
+		if f.desc {
+			get = append(inverted(9), "x, m, err := lex.Read"+name+"(s)")
+		}
+		get = append(get, checkErr...)
+		if k != t {
+			get = append(get, "if "+overflow(k, t, "x")[len(" || "):]+" {", errInvalid, "}")
+		}
+		return encoding{
+			size: fmt.Sprintf("lex.%sSize(%s(%s))", name, t, val),
+			put:  []string{fmt.Sprintf("n += lex.Put%s(b[n:], %s(%s))", name, t, val)},
+			get:  append(get, f.assign("x", t), "n += m"),
+		}
+
+	case f.memcomparable && (k == "bool" || isInt(k) || isUint(k)):
+		name, t := "Int64", "int64"
+		if isUint(k) {
+			name, t = "Uint64", "uint64"
+		}
+		e := encoding{size: "8", nativeDesc: true}
+		e.get = append([]string{"x, m, err := lex.Read" + name + d + "(b[n:])"}, checkErr...)
+		if k == "bool" {
+			e.put = []string{
+				"if " + val + " {",
+				"lex.Put" + name + d + "(b[n:], 1)",
+				"} else {",
+				"lex.Put" + name + d + "(b[n:], 0)",
+				"}",
+				"n += 8",
+			}
+			e.get = append(e.get, f.assign("x != 0", k), "n += m")
+			return e
+		}
+		e.put = []string{
+			fmt.Sprintf("lex.Put%s%s(b[n:], %s(%s))", name, d, t, val),
+			"n += 8",
+		}
+		if k != t {
+			e.get = append(e.get, "if "+overflow(k, t, "x")[len(" || "):]+" {", errInvalid, "}")
+		}
+		e.get = append(e.get, f.assign("x", t), "n += m")
+		return e
+
+	case f.memcomparable && isFloat(k):
+		get := []string{"x, m, err := lex.ReadMemcomparableFloat64(b[n:])"}
+		if f.desc {
+			get = append(inverted(8), "x, m, err := lex.ReadMemcomparableFloat64(s)")
+		}
+		get = append(get, checkErr...)
+		return encoding{
+			size: "8",
+			put: []string{
+				fmt.Sprintf("lex.PutMemcomparableFloat64(b[n:], float64(%s))", val),
+				"n += 8",
+			},
+			get: append(get, f.assign("x", "float64"), "n += m"),
+		}
+
+	case f.memcomparable && (k == "string" || k == "bytes"):
+		e := encoding{nativeDesc: true}
+		if k == "string" {
+			e.size = fmt.Sprintf("(len(%s)/8+1)*9", val)
+			e.put = []string{
+				fmt.Sprintf("lex.PutMemcomparableBytes%s(b[n:], []byte(%s))", d, val),
+				"n += " + e.size,
+			}
+		} else {
+			e.size = fmt.Sprintf("lex.MemcomparableBytesSize(%s)", val)
+			e.put = []string{
+				fmt.Sprintf("lex.PutMemcomparableBytes%s(b[n:], %s)", d, val),
+				"n += " + e.size,
+			}
+		}
+		e.get = append([]string{"x, m, err := lex.ReadMemcomparableBytes" + d + "(b[n:])"}, checkErr...)
+		e.get = append(e.get, f.assign("x", "bytes"), "n += m")
+		return e
+
+	case k == "string" && f.escaped:
+		get := append([]string{"x, m, err := lex.ReadEscapedString" + d + "(b[n:])"}, checkErr...)
+		return encoding{
+			size: fmt.Sprintf("lex.EscapedStringSize(%s)", f.conv()),
+			put: []string{
+				fmt.Sprintf("lex.PutEscapedString%s(b[n:], %s)", d, f.conv()),
+				fmt.Sprintf("n += lex.EscapedStringSize(%s)", f.conv()),
+			},
+			get:        append(get, f.assign("x", k), "n += m"),
+			nativeDesc: true,
+		}
+
+	case k == "string":
+		get := append([]string{"x, m, err := lex.ReadString" + d + "(b[n:])"}, checkErr...)
+		return encoding{
+			size: fmt.Sprintf("len(%s) + 1", val),
+			put: []string{
+				fmt.Sprintf("lex.PutString%s(b[n:], %s)", d, f.conv()),
+				fmt.Sprintf("n += len(%s) + 1", val),
+			},
+			get:        append(get, f.assign("x", k), "n += m"),
+			nativeDesc: true,
+		}
+
+	case k == "bytes":
+		get := append([]string{"x, m, err := lex.ReadEscapedBytes" + d + "(b[n:])"}, checkErr...)
+		return encoding{
+			size: fmt.Sprintf("lex.EscapedBytesSize(%s)", val),
+			put: []string{
+				fmt.Sprintf("lex.PutEscapedBytes%s(b[n:], %s)", d, val),
+				fmt.Sprintf("n += lex.EscapedBytesSize(%s)", val),
+			},
+			get:        append(get, f.assign("x", k), "n += m"),
+			nativeDesc: true,
+		}
+
+	case k == "time":
+		g.imports["time"] = true
+		get := []string{"x, m, err := lex.ReadTime(b[n:], time.Nanosecond)"}
+		if f.desc {
+			get = append(inverted(12), "x, m, err := lex.ReadTime(s, time.Nanosecond)")
+		}
+		get = append(get, checkErr...)
+		return encoding{
+			size: "lex.TimeSize(time.Nanosecond)",
+			put: []string{
+				fmt.Sprintf("lex.PutTime(b[n:], %s, time.Nanosecond)", f.conv()),
+				"n += lex.TimeSize(time.Nanosecond)",
+			},
+			get: append(get, f.assign("x", k), "n += m"),
+		}
+
+	case k == "decimal":
+		get := append([]string{"x, m, err := lex.ReadDecimal" + d + "(b[n:])"}, checkErr...)
+		return encoding{
+			size: fmt.Sprintf("lex.DecimalSize(%s)", f.conv()),
+			put: []string{
+				fmt.Sprintf("lex.PutDecimal(b[n:], %s)", f.conv()),
+				fmt.Sprintf("n += lex.DecimalSize(%s)", f.conv()),
+			},
+			get: append(get, f.assign("x", k), "n += m"),
+		}
+
+	case k == "struct":
+		decode := "DecodeKey"
+		if f.desc {
+			decode = "decodeKeyDesc"
+		}
+		get := append([]string{"m, err := " + val + "." + decode + "(b[n:])"}, checkErr...)
+		return encoding{
+			size: val + ".KeySize()",
+			put:  []string{fmt.Sprintf("n = len(%s.AppendKey(b[:n]))", val)},
+			get:  append(get, "n += m"),
+		}
+	}
+
+	p := fixed[k]
+	get := append([]string{fmt.Sprintf("x, m, err := lex.Read%s%s(b[n:])", p.name, d)}, checkErr...)
+	return encoding{
+		size: strconv.Itoa(p.size),
+		put: []string{
+			fmt.Sprintf("lex.Put%s%s(b[n:], %s)", p.name, d, f.conv()),
+			fmt.Sprintf("n += %d", p.size),
+		},
+		get:        append(get, f.assign("x", k), "n += m"),
+		nativeDesc: true,
+	}
+}
+
+// overflow returns a condition, prefixed with ||, that is true if x of type t cannot be represented by kind.
+func overflow(kind, t, x string) string {
+	if kind == t {
+		return ""
+	}
+	return fmt.Sprintf(" || %s(%s(%s)) != %s", t, kind, x, x)
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+func (g *generator) lines(ls []string) {
+	for _, l := range ls {
+		g.printf("%s\n", l)
+	}
+}
+
+// header writes the file header, package clause and imports, followed by body.
+func (g *generator) header(types []string, imports []string, body []byte) ([]byte, error) {
+	g.buf.Reset()
+	g.printf("// Code generated by \"lexgen -type=%s\"; DO NOT EDIT.\n\n", strings.Join(types, ","))
+	g.printf("package %s\n\n", g.pkg)
+	g.printf("import (\n")
+	for _, p := range imports {
+		if p == "" {
+			g.printf("\n")
+			continue
+		}
+		g.printf("%q\n", p)
+	}
+	g.printf(")\n")
+	g.buf.Write(body)
+
+	src, err := format.Source(g.buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("invalid generated code: %v", err)
+	}
+	return src, nil
+}
+
+// sortedImports returns the imports used by the generated code, with lex last.
+func (g *generator) sortedImports() []string {
+	var ps []string
+	for p := range g.imports {
+		if p != lexPath {
+			ps = append(ps, p)
+		}
+	}
+	sort.Strings(ps)
+	if g.imports[lexPath] {
+		ps = append(ps, "", lexPath)
+	}
+	return ps
+}
+
+// generate returns the source of the KeySize, AppendKey and DecodeKey methods for each of types.
+func (g *generator) generate(types []string) ([]byte, error) {
+	if err := g.reset(types); err != nil {
+		return nil, err
+	}
+
+	fss := make(map[string][]field, len(types))
+	for _, t := range types {
+		fs, err := g.fields(t)
+		if err != nil {
+			return nil, err
+		}
+		fss[t] = fs
+	}
+	desc := inverts(types, fss)
+
+	var body bytes.Buffer
+	for _, t := range types {
+		g.buf.Reset()
+		g.method(t, fss[t], desc[t])
+		body.Write(g.buf.Bytes())
+	}
+	return g.header(types, g.sortedImports(), body.Bytes())
+}
+
+// inverts returns the types among those generated that are decoded in descending order,
+// as nested within another type with a desc tag, or with an odd number of them.
+func inverts(types []string, fss map[string][]field) map[string]bool {
+	desc := make(map[string]bool)
+	var visit func(t string, inv bool)
+	visit = func(t string, inv bool) {
+		for _, f := range fss[t] {
+			if f.kind != "struct" || f.marshaler {
+				continue
+			}
+			if d := f.desc != inv; d && !desc[f.typ] {
+				desc[f.typ] = true
+				visit(f.typ, true)
+			}
+		}
+	}
+	for _, t := range types {
+		visit(t, false)
+	}
+	return desc
+}
+
+func (g *generator) reset(types []string) error {
+	g.types = make(map[string]bool)
+	g.imports = make(map[string]bool)
+	for _, t := range types {
+		if t == "" {
+			return errors.New("empty type name")
+		}
+		g.types[t] = true
+	}
+	return nil
+}
+
+// method writes the methods for type t with fields fs, and if desc is set, a method decoding t in descending order.
+func (g *generator) method(t string, fs []field, desc bool) {
+	es := make([]encoding, len(fs))
+	sizes := make([]string, len(fs))
+	for i, f := range fs {
+		es[i] = g.encoding(t, f)
+		sizes[i] = es[i].size
+		if f.nullable {
+			sizes[i] += " + 1"
+		}
+	}
+
+	g.printf("\n//KeySize returns the number of bytes AppendKey appends to encode v, as per lex.Size.\n")
+	g.printf("func (v %s) KeySize() int {\n", t)
+	g.printf("return %s\n", strings.Join(sizes, " + "))
+	g.printf("}\n")
+
+	g.printf("\n//AppendKey appends the encoding of v to b, as per lex.PutReflect, and returns the extended slice.\n")
+	g.printf("func (v %s) AppendKey(b []byte) []byte {\n", t)
+	g.printf("n := len(b)\n")
+	g.printf("b = append(b, make([]byte, v.KeySize())...)\n")
+	for i, f := range fs {
+		g.printf("\n")
+		if f.nullable {
+			g.printf("b[n] = 0x01\n")
+			g.printf("n++\n")
+		}
+		if !f.desc || es[i].nativeDesc {
+			if es[i].scoped {
+				g.printf("{\n")
+				g.lines(es[i].put)
+				g.printf("}\n")
+				continue
+			}
+			g.lines(es[i].put)
+			continue
+		}
+		g.printf("{\n")
+		g.printf("m := n\n")
+		g.lines(es[i].put)
+		g.printf("for i := m; i < n; i++ {\n")
+		g.printf("b[i] = ^b[i]\n")
+		g.printf("}\n")
+		g.printf("}\n")
+	}
+	g.printf("return b\n")
+	g.printf("}\n")
+
+	g.printf("\n//DecodeKey decodes v from the start of b, as per lex.Reflect, returning the number of bytes read.\n")
+	g.printf("func (v *%s) DecodeKey(b []byte) (int, error) {\n", t)
+	g.decode(fs, es, false)
+	if desc {
+		for i, f := range fs {
+			f.desc = !f.desc
+			es[i] = g.encoding(t, f)
+		}
+		g.printf("\n//decodeKeyDesc decodes v from the start of b, as per DecodeKey, with each byte of b inverted as it is read.\n")
+		g.printf("func (v *%s) decodeKeyDesc(b []byte) (int, error) {\n", t)
+		g.decode(fs, es, true)
+	}
+}
+
+// decode writes the body of a method decoding fields fs with encodings es.
+// If inv is set, the struct is decoded in descending order, so null markers are also inverted, as by lex.Desc.
+func (g *generator) decode(fs []field, es []encoding, inv bool) {
+	g.printf("n := 0\n")
+	for i, f := range fs {
+		g.printf("\n")
+		if f.nullable {
+			g.imports[lexPath] = true
+			notNull := "0x01"
+			if inv {
+				notNull = "0xFE"
+			}
+			g.printf("if len(b) <= n {\nreturn 0, lex.ErrShortBuffer\n}\n")
+			g.printf("if b[n] != %s {\n%s\n}\n", notNull, errInvalid)
+			g.printf("n++\n")
+		}
+		g.printf("{\n")
+		g.lines(es[i].get)
+		g.printf("}\n")
+	}
+	g.printf("return n, nil\n")
+	g.printf("}\n")
+}
+
+// generateTest returns the source of a test checking the generated methods for each of types against lex.Key and lex.Reflect.
+func (g *generator) generateTest(types []string) ([]byte, error) {
+	if err := g.reset(types); err != nil {
+		return nil, err
+	}
+	g.imports["bytes"] = true
+	g.imports["reflect"] = true
+	g.imports["testing"] = true
+	g.imports[lexPath] = true
+
+	var body bytes.Buffer
+	for _, t := range types {
+		fs, err := g.fields(t)
+		if err != nil {
+			return nil, err
+		}
+		g.buf.Reset()
+		g.test(t, fs)
+		body.Write(g.buf.Bytes())
+	}
+	return g.header(types, g.sortedImports(), body.Bytes())
+}
+
+// test writes a test of the methods for type t with fields fs, using the zero value and two sample values.
+func (g *generator) test(t string, fs []field) {
+	g.printf("\nfunc Test%s_lexgen(t *testing.T) {\n", t)
+	g.printf("for _, v := range []%s{\n", t)
+	g.printf("{},\n")
+	for s := 0; s < 2; s++ {
+		g.printf("{\n")
+		for _, f := range fs {
+			if x := g.sample(f, s); x != "" {
+				g.printf("%s: %s,\n", f.name, x)
+			}
+		}
+		g.printf("},\n")
+	}
+	g.printf("} {\n")
+	g.printf(`k, err := lex.Key(v)
+if err != nil {
+	t.Fatal(err)
+}
+if n := v.KeySize(); n != len(k) {
+	t.Errorf("%%+v: KeySize() = %%d, want %%d", v, n, len(k))
+}
+if b := v.AppendKey([]byte{0xFF}); b[0] != 0xFF || !bytes.Equal(b[1:], k) {
+	t.Errorf("%%+v: AppendKey() = %%x, want %%x", v, b[1:], k)
+}
+
+var got, want %s
+n, err := got.DecodeKey(k)
+if err != nil || n != len(k) {
+	t.Errorf("%%x: DecodeKey() = %%d, %%v, want %%d, nil", k, n, err, len(k))
+}
+if err := lex.Reflect(k, &want); err != nil {
+	t.Fatal(err)
+}
+if !reflect.DeepEqual(got, want) {
+	t.Errorf("%%x: DecodeKey() = %%+v, want %%+v", k, got, want)
+}
+
+//truncated keys are rejected with the same error as lex.Reflect
+for i := 0; i < len(k); i++ {
+	var got, want %s
+	_, err := got.DecodeKey(k[:i])
+	if werr := lex.Reflect(k[:i], &want); err != werr {
+		t.Errorf("%%x: DecodeKey() error = %%v, want %%v", k[:i], err, werr)
+	}
+}
+}
+}
+`, t, t)
+}
+
+// sample returns the sth sample value for f, or "" to leave the field zero.
+func (g *generator) sample(f field, s int) string {
+	var x string
+	switch k := f.kind; {
+	case k == "bool":
+		x = []string{"true", "false"}[s]
+	case isInt(k):
+		x = []string{"-2", "3"}[s]
+	case isUint(k):
+		x = []string{"2", "3"}[s]
+	case isFloat(k):
+		x = []string{"-1.5", "2.25"}[s]
+	case k == "complex64" || k == "complex128":
+		x = []string{"1 + 2i", "-3i"}[s]
+	case k == "string":
+		x = []string{`"a"`, `"abcdefghij"`}[s]
+		if f.escaped || f.memcomparable {
+			x = []string{`"a\x00b"`, `"abcdefghij"`}[s]
+		}
+	case k == "bytes":
+		x = []string{`[]byte("a\x00b")`, `[]byte("abcdefghij")`}[s]
+	case k == "time":
+		g.imports["time"] = true
+		x = []string{"time.Unix(1, 2)", "time.Unix(-1, 0)"}[s]
+	default:
+		return ""
+	}
+	if f.typ != f.goType() {
+		x = f.typ + "(" + x + ")"
+	}
+	return x
+}
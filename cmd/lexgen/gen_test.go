@@ -0,0 +1,145 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerate_example(t *testing.T) {
+	g, err := load("example")
+	assert.Nil(t, err)
+	types := []string{"Index", "Span", "Event"}
+
+	src, err := g.generate(types)
+	assert.Nil(t, err)
+	want, err := os.ReadFile(filepath.Join("example", "index_lex.go"))
+	assert.Nil(t, err)
+	assert.Equal(t, string(want), string(src), "example/index_lex.go is out of date; run go generate")
+
+	src, err = g.generateTest(types)
+	assert.Nil(t, err)
+	want, err = os.ReadFile(filepath.Join("example", "index_lex_test.go"))
+	assert.Nil(t, err)
+	assert.Equal(t, string(want), string(src), "example/index_lex_test.go is out of date; run go generate")
+}
+
+func TestGenerate_invalid(t *testing.T) {
+	dir := t.TempDir()
+	src := `package p
+
+type named struct{ A int }
+
+type (
+	marshalOnly   int
+	unmarshalOnly int
+	ptrMarshal    int
+	custom        int
+)
+
+func (marshalOnly) MarshalLex(dst []byte) []byte         { return dst }
+func (*unmarshalOnly) UnmarshalLex(b []byte) (int, error) { return 0, nil }
+func (*ptrMarshal) MarshalLex(dst []byte) []byte          { return dst }
+func (*ptrMarshal) UnmarshalLex(b []byte) (int, error)    { return 0, nil }
+func (custom) MarshalLex(dst []byte) []byte               { return dst }
+func (*custom) UnmarshalLex(b []byte) (int, error)        { return 0, nil }
+
+type (
+	Pointer  struct{ A *int }
+	Map      struct{ A map[string]int }
+	Float    struct{ A float64 ` + "`lex:\"number\"`" + ` }
+	Nested   struct{ A named }
+	Varint   struct{ A named ` + "`lex:\"varint\"`" + ` }
+	BadPos   struct{ A int ` + "`lex:\"pos=x\"`" + ` }
+	Skipped  struct{ A int ` + "`lex:\"-\"`" + ` }
+	Blank    struct{ _ int }
+	Unknown  struct{ A int ` + "`lex:\"dsc\"`" + ` }
+	DupPos   struct {
+		A int ` + "`lex:\"pos=1\"`" + `
+		B int ` + "`lex:\"pos=1\"`" + `
+	}
+	FarPos   struct{ A int ` + "`lex:\"pos=1\"`" + ` }
+	Private  struct {
+		A int
+		b int ` + "`lex:\"desc\"`" + `
+	}
+	MarshalOnly   struct{ A marshalOnly }
+	UnmarshalOnly struct{ A unmarshalOnly }
+	PtrMarshal    struct{ A ptrMarshal }
+	CustomVarint  struct{ A custom ` + "`lex:\"varint\"`" + ` }
+	NotAStruct int
+)
+`
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "p.go"), []byte(src), 0644))
+	g, err := load(dir)
+	assert.Nil(t, err)
+
+	for _, types := range [][]string{
+		{"Pointer"}, {"Map"}, {"Float"}, {"Nested"}, {"named", "Varint"},
+		{"BadPos"}, {"Skipped"}, {"Blank"}, {"Unknown"}, {"DupPos"}, {"FarPos"}, {"Private"},
+		{"MarshalOnly"}, {"UnmarshalOnly"}, {"PtrMarshal"}, {"CustomVarint"},
+		{"NotAStruct"}, {"Missing"}, {""},
+	} {
+		_, err := g.generate(types)
+		assert.NotNil(t, err, "%v", types)
+	}
+}
+
+func TestGenerate_marshaler(t *testing.T) {
+	dir := t.TempDir()
+	src := `package p
+
+type custom [2]int
+
+func (custom) MarshalLex(dst []byte) []byte        { return dst }
+func (*custom) UnmarshalLex(b []byte) (int, error) { return 0, nil }
+
+type Custom struct {
+	A custom ` + "`lex:\"nullslast\"`" + `
+}
+`
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "p.go"), []byte(src), 0644))
+	g, err := load(dir)
+	assert.Nil(t, err)
+
+	//types otherwise unsupported are encoded by their MarshalLex method, without lex.Sizer
+	out, err := g.generate([]string{"Custom"})
+	assert.Nil(t, err)
+	assert.Contains(t, string(out), "len(v.A.MarshalLex(nil)) + 1")
+	assert.Contains(t, string(out), "e := v.A.MarshalLex(b[n : n : n+s])")
+	assert.Contains(t, string(out), "if len(e) != s {")
+	assert.Contains(t, string(out), "m, err := v.A.UnmarshalLex(b[n:])")
+}
+
+func TestGenerate_desc(t *testing.T) {
+	dir := t.TempDir()
+	src := `package p
+
+type Inner struct {
+	A int ` + "`lex:\"nullsfirst\"`" + `
+	B int64 ` + "`lex:\"varint\"`" + `
+}
+
+type Outer struct {
+	I Inner ` + "`lex:\"desc\"`" + `
+}
+`
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "p.go"), []byte(src), 0644))
+	g, err := load(dir)
+	assert.Nil(t, err)
+
+	//a descending struct is decoded in place, with its null markers inverted, rather than from an inverted copy
+	out, err := g.generate([]string{"Outer", "Inner"})
+	assert.Nil(t, err)
+	assert.Contains(t, string(out), "m, err := v.I.decodeKeyDesc(b[n:])")
+	assert.Contains(t, string(out), "func (v *Inner) decodeKeyDesc(b []byte) (int, error) {")
+	assert.Contains(t, string(out), "if b[n] != 0xFE {")
+	assert.Contains(t, string(out), "var a [9]byte")
+	assert.NotContains(t, string(out), "make([]byte, len(b)-n)")
+
+	out, err = g.generate([]string{"Inner"})
+	assert.Nil(t, err)
+	assert.NotContains(t, string(out), "decodeKeyDesc")
+}
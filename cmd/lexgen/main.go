@@ -0,0 +1,94 @@
+//Lexgen generates reflection-free key codecs for struct types, byte-identical to lex.PutReflect and lex.Reflect.
+//
+//For each named struct type T, lexgen writes the methods
+//
+//	func (v T) KeySize() int
+//	func (v T) AppendKey(b []byte) []byte
+//	func (v *T) DecodeKey(b []byte) (int, error)
+//
+//which call the lex primitives directly, so that encoding a key neither allocates nor uses reflection.
+//DecodeKey returns the same errors as lex.Reflect, such as lex.ErrShortBuffer, and reads descending values in place,
+//so that it allocates only for values such as strings.
+//Lex struct tags are honoured as by lex.PutReflect. Supported field types are Boolean and Numeric types, string, []byte,
+//time.Time, lex.Decimal and types defined in terms of them, and struct types for which lexgen is also run.
+//Types declared in the package with both a MarshalLex method, on a value receiver, and an UnmarshalLex method
+//are encoded by those methods, sized by LexSize if declared, as by lex.PutReflect.
+//As lex.Key returns an error if MarshalLex appends other than that size, AppendKey panics.
+//The number tag is not supported for floats.
+//
+//Typical usage is via go:generate, as in
+//
+//	//go:generate go run github.com/xcdb/lex/cmd/lexgen -type=Index
+//
+//which writes the methods to index_lex.go. With -test, lexgen also writes index_lex_test.go,
+//checking the generated methods against lex.Key and lex.Reflect.
+//
+//Usage:
+//
+//	lexgen -type T[,T...] [-output file] [-test] [directory]
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	typeNames = flag.String("type", "", "comma-separated list of struct type names; must be set")
+	output    = flag.String("output", "", "output file name; default <type>_lex.go")
+	test      = flag.Bool("test", false, "also write a test checking the generated methods against the reflection path")
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: lexgen -type T[,T...] [-output file] [-test] [directory]\n")
+	flag.PrintDefaults()
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("lexgen: ")
+	flag.Usage = usage
+	flag.Parse()
+	if *typeNames == "" || flag.NArg() > 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	types := strings.Split(*typeNames, ",")
+
+	dir := "."
+	if flag.NArg() == 1 {
+		dir = flag.Arg(0)
+	}
+
+	g, err := load(dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	src, err := g.generate(types)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	name := *output
+	if name == "" {
+		name = filepath.Join(dir, strings.ToLower(types[0])+"_lex.go")
+	}
+	if err := os.WriteFile(name, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+
+	if *test {
+		src, err := g.generateTest(types)
+		if err != nil {
+			log.Fatal(err)
+		}
+		name = strings.TrimSuffix(name, ".go") + "_test.go"
+		if err := os.WriteFile(name, src, 0644); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,816 @@
+package lex
+
+import (
+	"errors"
+	"math/big"
+	"reflect"
+	"sync"
+	"time"
+)
+
+//codec is a compiled plan for encoding and decoding values of a single type with a given set of options.
+//Size and put return the number of bytes generated, or -1 if the value is invalid.
+//Get returns the number of bytes read, or an error such as ErrShortBuffer if b does not hold a valid encoding.
+//Each byte of b is combined with mask as it is read, so that descending values are decoded in place, without an inverted copy.
+//Err records why the type cannot be encoded, such as an invalid struct tag, if known.
+type codec struct {
+	size func(v reflect.Value) int
+	put  func(b []byte, v reflect.Value) int
+	get  func(b []byte, v reflect.Value, mask byte) (int, error)
+	err  error
+}
+
+//errUnsupported is returned when decoding a type that has no encoding.
+var errUnsupported = errors.New("lex: unsupported type")
+
+type codecKey struct {
+	t reflect.Type
+	o opts
+}
+
+//codecs caches a *codec for each codecKey, as compiled on first use.
+var codecs sync.Map
+
+//codecFor returns the compiled codec for values of type t with options o.
+func codecFor(t reflect.Type, o opts) *codec {
+	k := codecKey{t, o}
+	if c, ok := codecs.Load(k); ok {
+		return c.(*codec)
+	}
+
+	//codecs for recursive types refer to themselves, so are registered before being compiled
+	seen := make(map[codecKey]*codec)
+	c := compile(k, seen)
+	for k, c := range seen {
+		codecs.LoadOrStore(k, c)
+	}
+	return c
+}
+
+//compile returns the codec for k, using any codec already compiled or being compiled in seen.
+func compile(k codecKey, seen map[codecKey]*codec) *codec {
+	if c, ok := codecs.Load(k); ok {
+		return c.(*codec)
+	}
+	if c, ok := seen[k]; ok {
+		return c
+	}
+	c := &codec{}
+	seen[k] = c
+
+	switch t, o := k.t, k.o; {
+	case o&optNullable != 0:
+		compileNullable(c, t, o, compile(codecKey{t, o &^ optNullable}, seen))
+	case o&optDesc != 0:
+		compileDesc(c, compile(codecKey{t, o &^ optDesc}, seen))
+	case t.Kind() == reflect.Ptr:
+		compilePtr(c, t, compile(codecKey{t.Elem(), o}, seen))
+	default:
+		compileValue(c, t, o, seen)
+	}
+	return c
+}
+
+func invalid(reflect.Value) int                                    { return -1 }
+func invalidPut(b []byte, v reflect.Value) int                     { return -1 }
+func invalidGet(b []byte, v reflect.Value, mask byte) (int, error) { return 0, errUnsupported }
+
+//compileNullable precedes each value with a marker, such that nil sorts before or after all other values.
+func compileNullable(c *codec, t reflect.Type, o opts, inner *codec) {
+	c.err = inner.err
+	marker := nullMarker(o)
+	c.size = func(v reflect.Value) int {
+		if isNil(v) {
+			return 1
+		}
+		n := inner.size(v)
+		if n < 0 {
+			return -1
+		}
+		return n + 1
+	}
+	c.put = func(b []byte, v reflect.Value) int {
+		if isNil(v) {
+			b[0] = marker
+			return 1
+		}
+		b[0] = notNull
+		n := inner.put(b[1:], v)
+		if n < 0 {
+			return -1
+		}
+		return n + 1
+	}
+	c.get = func(b []byte, v reflect.Value, mask byte) (int, error) {
+		if len(b) == 0 {
+			return 0, ErrShortBuffer
+		}
+		switch b[0] ^ mask {
+		case marker:
+			if t.Kind() != reflect.Ptr {
+				return 0, ErrInvalid
+			}
+			v.Set(reflect.Zero(t))
+			return 1, nil
+		case notNull:
+			n, err := inner.get(b[1:], v, mask)
+			if err != nil {
+				return 0, err
+			}
+			return n + 1, nil
+		}
+		return 0, ErrInvalid
+	}
+}
+
+//compileDesc inverts the ascending encoding of each value.
+func compileDesc(c *codec, inner *codec) {
+	c.err = inner.err
+	c.size = func(v reflect.Value) int {
+		return inner.size(v)
+	}
+	c.put = func(b []byte, v reflect.Value) int {
+		n := inner.put(b, v)
+		if n > 0 {
+			invert(b[:n])
+		}
+		return n
+	}
+	c.get = func(b []byte, v reflect.Value, mask byte) (int, error) {
+		return inner.get(b, v, ^mask)
+	}
+}
+
+//compilePtr encodes the value pointed to, allocating it when decoding if necessary.
+//When encoding, only a single level of indirection is supported.
+func compilePtr(c *codec, t reflect.Type, elem *codec) {
+	c.err = elem.err
+	deref := t.Elem().Kind() != reflect.Ptr
+	c.size = func(v reflect.Value) int {
+		if m := marshaler(v); m != nil {
+			return marshalerSize(m)
+		}
+		if v.IsNil() {
+			return -1
+		}
+		if !deref {
+			if m := marshaler(v.Elem()); m != nil {
+				return marshalerSize(m)
+			}
+			return -1
+		}
+		return elem.size(v.Elem())
+	}
+	c.put = func(b []byte, v reflect.Value) int {
+		if m := marshaler(v); m != nil {
+			return putMarshaler(b, m, marshalerSize(m))
+		}
+		if v.IsNil() {
+			return -1
+		}
+		if !deref {
+			if m := marshaler(v.Elem()); m != nil {
+				return putMarshaler(b, m, marshalerSize(m))
+			}
+			return -1
+		}
+		return elem.put(b, v.Elem())
+	}
+	c.get = func(b []byte, v reflect.Value, mask byte) (int, error) {
+		if v.IsNil() {
+			v.Set(reflect.New(t.Elem()))
+		}
+		return elem.get(b, v.Elem(), mask)
+	}
+}
+
+//compileValue compiles a codec for a type other than a pointer, with options o other than optNullable and optDesc.
+//Marshaler and Unmarshaler implementations take precedence over the encoding selected by kind.
+func compileValue(c *codec, t reflect.Type, o opts, seen map[codecKey]*codec) {
+	compileKind(c, t, o, seen)
+
+	if t.Implements(marshalerType) || reflect.PtrTo(t).Implements(marshalerType) {
+		size, put := c.size, c.put
+		c.size = func(v reflect.Value) int {
+			if m := marshaler(v); m != nil {
+				return marshalerSize(m)
+			}
+			return size(v)
+		}
+		c.put = func(b []byte, v reflect.Value) int {
+			if m := marshaler(v); m != nil {
+				return putMarshaler(b, m, marshalerSize(m))
+			}
+			return put(b, v)
+		}
+	}
+	if reflect.PtrTo(t).Implements(unmarshalerType) {
+		get := c.get
+		c.get = func(b []byte, v reflect.Value, mask byte) (int, error) {
+			if u := unmarshaler(v); u != nil {
+				//the extent of a custom encoding is unknown until it has been read, so all of b is unmasked
+				if mask != 0 {
+					b = appendMasked(make([]byte, 0, len(b)), b, mask)
+				}
+				n, err := u.UnmarshalLex(b)
+				if err != nil {
+					return 0, err
+				}
+				if n < 0 || n > len(b) {
+					return 0, ErrInvalid
+				}
+				return n, nil
+			}
+			return get(b, v, mask)
+		}
+	}
+}
+
+//compileKind compiles a codec for t by kind, with any encoding selected by o.
+func compileKind(c *codec, t reflect.Type, o opts, seen map[codecKey]*codec) {
+	c.size, c.put, c.get = invalid, invalidPut, invalidGet
+
+	k := t.Kind()
+	switch {
+	case o&optNumber != 0 && (isInt(k) || isUint(k) || isFloat(k)):
+		compileNumber(c, k)
+	case o&optVarint != 0 && (isInt(k) || isUint(k)):
+		compileVarint(c, k)
+	case o&optMemcomparable != 0 && (k == reflect.Bool || isInt(k) || isUint(k) || isFloat(k) || k == reflect.String || isBytes(t)):
+		compileMemcomparable(c, t)
+	case k == reflect.String:
+		compileString(c, o)
+	case isBytes(t):
+		compileBytes(c, o)
+	case k == reflect.Slice:
+		compileSlice(c, t, compile(codecKey{t.Elem(), o}, seen))
+	case k == reflect.Array:
+		compileArray(c, t, compile(codecKey{t.Elem(), o}, seen))
+	case t == timeType:
+		compileTime(c)
+	case t == bigIntType:
+		compileBigInt(c)
+	case t == decimalType:
+		compileDecimal(c)
+	case k == reflect.Struct:
+		compileStruct(c, t, o, seen)
+	default:
+		compileFixed(c, k, int(t.Size()))
+	}
+}
+
+func isInt(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Int64
+}
+
+func isUint(k reflect.Kind) bool {
+	return k >= reflect.Uint && k <= reflect.Uint64
+}
+
+func isFloat(k reflect.Kind) bool {
+	return k == reflect.Float32 || k == reflect.Float64
+}
+
+func isBytes(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8
+}
+
+func compileNumber(c *codec, k reflect.Kind) {
+	switch {
+	case isInt(k):
+		c.size = func(v reflect.Value) int { return NumberInt64Size(v.Int()) }
+		c.put = func(b []byte, v reflect.Value) int { return PutNumberInt64(b, v.Int()) }
+	case isUint(k):
+		c.size = func(v reflect.Value) int { return NumberUint64Size(v.Uint()) }
+		c.put = func(b []byte, v reflect.Value) int { return PutNumberUint64(b, v.Uint()) }
+	default:
+		c.size = func(v reflect.Value) int { return NumberFloat64Size(v.Float()) }
+		c.put = func(b []byte, v reflect.Value) int { return PutNumberFloat64(b, v.Float()) }
+	}
+	c.get = func(b []byte, v reflect.Value, mask byte) (int, error) {
+		x, n, err := readNumber(b, mask)
+		if err != nil {
+			return 0, err
+		}
+		if !setNumber(v, x) {
+			return 0, ErrInvalid
+		}
+		return n, nil
+	}
+}
+
+func compileVarint(c *codec, k reflect.Kind) {
+	if isInt(k) {
+		c.size = func(v reflect.Value) int { return VarintSize(v.Int()) }
+		c.put = func(b []byte, v reflect.Value) int { return PutVarint(b, v.Int()) }
+		c.get = func(b []byte, v reflect.Value, mask byte) (int, error) {
+			var t [9]byte
+			x, n, err := ReadVarint(masked(t[:], b, mask))
+			if err != nil {
+				return 0, err
+			}
+			if v.OverflowInt(x) {
+				return 0, ErrInvalid
+			}
+			v.SetInt(x)
+			return n, nil
+		}
+		return
+	}
+	c.size = func(v reflect.Value) int { return UvarintSize(v.Uint()) }
+	c.put = func(b []byte, v reflect.Value) int { return PutUvarint(b, v.Uint()) }
+	c.get = func(b []byte, v reflect.Value, mask byte) (int, error) {
+		var t [9]byte
+		x, n, err := ReadUvarint(masked(t[:], b, mask))
+		if err != nil {
+			return 0, err
+		}
+		if v.OverflowUint(x) {
+			return 0, ErrInvalid
+		}
+		v.SetUint(x)
+		return n, nil
+	}
+}
+
+func compileMemcomparable(c *codec, t reflect.Type) {
+	k := t.Kind()
+	switch {
+	case k == reflect.String:
+		c.size = func(v reflect.Value) int { return memcomparableSize(v.Len()) }
+		c.put = func(b []byte, v reflect.Value) int {
+			x := []byte(v.String())
+			PutMemcomparableBytes(b, x)
+			return MemcomparableBytesSize(x)
+		}
+		c.get = func(b []byte, v reflect.Value, mask byte) (int, error) {
+			x, n, err := memcomparable(b, mask)
+			if err != nil {
+				return 0, err
+			}
+			v.SetString(string(x))
+			return n, nil
+		}
+		return
+	case k == reflect.Slice:
+		c.size = func(v reflect.Value) int { return MemcomparableBytesSize(v.Bytes()) }
+		c.put = func(b []byte, v reflect.Value) int {
+			x := v.Bytes()
+			PutMemcomparableBytes(b, x)
+			return MemcomparableBytesSize(x)
+		}
+		c.get = func(b []byte, v reflect.Value, mask byte) (int, error) {
+			x, n, err := memcomparable(b, mask)
+			if err != nil {
+				return 0, err
+			}
+			v.SetBytes(x)
+			return n, nil
+		}
+		return
+	case k == reflect.Bool:
+		c.put = func(b []byte, v reflect.Value) int {
+			var x int64
+			if v.Bool() {
+				x = 1
+			}
+			PutInt64(b, x)
+			return 8
+		}
+	case isInt(k):
+		c.put = func(b []byte, v reflect.Value) int {
+			PutInt64(b, v.Int())
+			return 8
+		}
+	case isUint(k):
+		c.put = func(b []byte, v reflect.Value) int {
+			PutUint64(b, v.Uint())
+			return 8
+		}
+	default:
+		c.put = func(b []byte, v reflect.Value) int {
+			PutMemcomparableFloat64(b, v.Float())
+			return 8
+		}
+	}
+
+	c.size = func(reflect.Value) int { return 8 }
+	c.get = func(b []byte, v reflect.Value, mask byte) (int, error) {
+		if len(b) < 8 {
+			return 0, ErrShortBuffer
+		}
+		var t [8]byte
+		b = masked(t[:], b, mask)
+		switch {
+		case k == reflect.Bool:
+			v.SetBool(Int64(b) != 0)
+		case isInt(k):
+			x := Int64(b)
+			if v.OverflowInt(x) {
+				return 0, ErrInvalid
+			}
+			v.SetInt(x)
+		case isUint(k):
+			x := Uint64(b)
+			if v.OverflowUint(x) {
+				return 0, ErrInvalid
+			}
+			v.SetUint(x)
+		default:
+			v.SetFloat(MemcomparableFloat64(b))
+		}
+		return 8, nil
+	}
+}
+
+func compileString(c *codec, o opts) {
+	if o&optEscaped != 0 {
+		c.size = func(v reflect.Value) int { return EscapedStringSize(v.String()) }
+		c.put = func(b []byte, v reflect.Value) int {
+			s := v.String()
+			PutEscapedString(b, s)
+			return EscapedStringSize(s)
+		}
+		c.get = func(b []byte, v reflect.Value, mask byte) (int, error) {
+			s, n, err := readEscaped(b, mask)
+			if err != nil {
+				return 0, err
+			}
+			v.SetString(string(s))
+			return n, nil
+		}
+		return
+	}
+	c.size = func(v reflect.Value) int { return v.Len() + 1 }
+	c.put = func(b []byte, v reflect.Value) int {
+		s := v.String()
+		PutString(b, s)
+		return len(s) + 1
+	}
+	c.get = func(b []byte, v reflect.Value, mask byte) (int, error) {
+		read := ReadString
+		if mask != 0 {
+			read = ReadStringDesc
+		}
+		s, n, err := read(b)
+		if err != nil {
+			return 0, err
+		}
+		v.SetString(s)
+		return n, nil
+	}
+}
+
+func compileBytes(c *codec, o opts) {
+	c.size = func(v reflect.Value) int { return EscapedBytesSize(v.Bytes()) }
+	c.put = func(b []byte, v reflect.Value) int {
+		bs := v.Bytes()
+		PutEscapedBytes(b, bs)
+		return EscapedBytesSize(bs)
+	}
+	if o&optView != 0 {
+		c.get = func(b []byte, v reflect.Value, mask byte) (int, error) {
+			bs, n, err := readEscaped(b, mask)
+			if err != nil {
+				return 0, err
+			}
+			v.SetBytes(bs)
+			return n, nil
+		}
+		return
+	}
+	c.get = func(b []byte, v reflect.Value, mask byte) (int, error) {
+		read := ReadEscapedBytes
+		if mask != 0 {
+			read = ReadEscapedBytesDesc
+		}
+		bs, n, err := read(b)
+		if err != nil {
+			return 0, err
+		}
+		v.SetBytes(bs)
+		return n, nil
+	}
+}
+
+func compileSlice(c *codec, t reflect.Type, elem *codec) {
+	c.err = elem.err
+	c.size = func(v reflect.Value) int {
+		sum := 1
+		for i, n := 0, v.Len(); i < n; i++ {
+			s := elem.size(v.Index(i))
+			if s < 0 {
+				return -1
+			}
+			sum += s + 1
+		}
+		return sum
+	}
+	c.put = func(b []byte, v reflect.Value) int {
+		sum := 0
+		for i, n := 0, v.Len(); i < n; i++ {
+			b[sum] = sliceElem
+			s := elem.put(b[sum+1:], v.Index(i))
+			if s < 0 {
+				return -1
+			}
+			sum += s + 1
+		}
+		b[sum] = sliceEnd
+		return sum + 1
+	}
+	c.get = func(b []byte, v reflect.Value, mask byte) (int, error) {
+		s := reflect.MakeSlice(t, 0, 0)
+		sum := 0
+		for {
+			if sum >= len(b) {
+				return 0, ErrMissingTerminator
+			}
+			switch b[sum] ^ mask {
+			case sliceEnd:
+				v.Set(s)
+				return sum + 1, nil
+			case sliceElem:
+			default:
+				return 0, ErrInvalid
+			}
+
+			e := reflect.New(t.Elem()).Elem()
+			n, err := elem.get(b[sum+1:], e, mask)
+			if err != nil {
+				return 0, err
+			}
+			s = reflect.Append(s, e)
+			sum += n + 1
+		}
+	}
+}
+
+func compileArray(c *codec, t reflect.Type, elem *codec) {
+	c.err = elem.err
+	n := t.Len()
+	c.size = func(v reflect.Value) int {
+		sum := 0
+		for i := 0; i < n; i++ {
+			s := elem.size(v.Index(i))
+			if s < 0 {
+				return -1
+			}
+			sum += s
+		}
+		if sum == 0 {
+			return -1
+		}
+		return sum
+	}
+	c.put = func(b []byte, v reflect.Value) int {
+		sum := 0
+		for i := 0; i < n; i++ {
+			s := elem.put(b[sum:], v.Index(i))
+			if s < 0 {
+				return -1
+			}
+			sum += s
+		}
+		if sum == 0 {
+			return -1
+		}
+		return sum
+	}
+	c.get = func(b []byte, v reflect.Value, mask byte) (int, error) {
+		sum := 0
+		for i := 0; i < n; i++ {
+			s, err := elem.get(b[sum:], v.Index(i), mask)
+			if err != nil {
+				return 0, err
+			}
+			sum += s
+		}
+		if sum == 0 {
+			return 0, errUnsupported
+		}
+		return sum, nil
+	}
+}
+
+func compileTime(c *codec) {
+	c.size = func(v reflect.Value) int {
+		if !v.CanInterface() || !timeInRange(v.Interface().(time.Time), time.Nanosecond) {
+			return -1
+		}
+		return TimeSize(time.Nanosecond)
+	}
+	c.put = func(b []byte, v reflect.Value) int {
+		if !v.CanInterface() {
+			return -1
+		}
+		x := v.Interface().(time.Time)
+		if !timeInRange(x, time.Nanosecond) {
+			return -1
+		}
+		PutTime(b, x, time.Nanosecond)
+		return TimeSize(time.Nanosecond)
+	}
+	c.get = func(b []byte, v reflect.Value, mask byte) (int, error) {
+		var t [12]byte
+		x, n, err := ReadTime(masked(t[:], b, mask), time.Nanosecond)
+		if err != nil {
+			return 0, err
+		}
+		v.Set(reflect.ValueOf(x))
+		return n, nil
+	}
+}
+
+func compileBigInt(c *codec) {
+	c.size = func(v reflect.Value) int {
+		x := bigIntOf(v)
+		if x == nil {
+			return -1
+		}
+		return BigIntSize(x)
+	}
+	c.put = func(b []byte, v reflect.Value) int {
+		x := bigIntOf(v)
+		if x == nil {
+			return -1
+		}
+		PutBigInt(b, x)
+		return BigIntSize(x)
+	}
+	c.get = func(b []byte, v reflect.Value, mask byte) (int, error) {
+		x, n, err := readBigInt(b, mask)
+		if err != nil {
+			return 0, err
+		}
+		v.Addr().Interface().(*big.Int).Set(x)
+		return n, nil
+	}
+}
+
+func compileDecimal(c *codec) {
+	c.size = func(v reflect.Value) int {
+		if !v.CanInterface() {
+			return -1
+		}
+		return DecimalSize(v.Interface().(Decimal))
+	}
+	c.put = func(b []byte, v reflect.Value) int {
+		if !v.CanInterface() {
+			return -1
+		}
+		d := v.Interface().(Decimal)
+		PutDecimal(b, d)
+		return DecimalSize(d)
+	}
+	c.get = func(b []byte, v reflect.Value, mask byte) (int, error) {
+		d, n, err := readDecimal(b, mask)
+		if err != nil {
+			return 0, err
+		}
+		v.Set(reflect.ValueOf(d))
+		return n, nil
+	}
+}
+
+func compileStruct(c *codec, t reflect.Type, o opts, seen map[codecKey]*codec) {
+	fs, err := structFields(t)
+	if err != nil {
+		c.err = err
+		c.get = func(b []byte, v reflect.Value, mask byte) (int, error) { return 0, err }
+		return
+	}
+	cs := make([]*codec, len(fs))
+	for i, f := range fs {
+		cs[i] = compile(codecKey{t.Field(f.index).Type, o | f.o}, seen)
+		if c.err == nil {
+			c.err = cs[i].err
+		}
+	}
+
+	c.size = func(v reflect.Value) int {
+		sum := 0
+		for i, f := range fs {
+			s := cs[i].size(v.Field(f.index))
+			if s < 0 {
+				return -1
+			}
+			sum += s
+		}
+		if sum == 0 {
+			return -1
+		}
+		return sum
+	}
+	c.put = func(b []byte, v reflect.Value) int {
+		sum := 0
+		for i, f := range fs {
+			s := cs[i].put(b[sum:], v.Field(f.index))
+			if s < 0 {
+				return -1
+			}
+			sum += s
+		}
+		if sum == 0 {
+			return -1
+		}
+		return sum
+	}
+	c.get = func(b []byte, v reflect.Value, mask byte) (int, error) {
+		sum := 0
+		for i, f := range fs {
+			s, err := cs[i].get(b[sum:], v.Field(f.index), mask)
+			if err != nil {
+				return 0, err
+			}
+			sum += s
+		}
+		if sum == 0 {
+			return 0, errUnsupported
+		}
+		return sum, nil
+	}
+}
+
+//compileFixed compiles a codec for Boolean and Numeric kinds of fixed size n.
+//Other kinds, such as maps and interfaces, are not supported.
+func compileFixed(c *codec, k reflect.Kind, n int) {
+	switch k {
+	case reflect.Bool:
+		c.put = func(b []byte, v reflect.Value) int { PutBool(b, v.Bool()); return 1 }
+	case reflect.Int:
+		c.put = func(b []byte, v reflect.Value) int { PutInt(b, int(v.Int())); return 8 }
+	case reflect.Uint:
+		c.put = func(b []byte, v reflect.Value) int { PutUint(b, uint(v.Uint())); return 8 }
+	case reflect.Int8:
+		c.put = func(b []byte, v reflect.Value) int { PutInt8(b, int8(v.Int())); return n }
+	case reflect.Uint8:
+		c.put = func(b []byte, v reflect.Value) int { PutUint8(b, uint8(v.Uint())); return n }
+	case reflect.Int16:
+		c.put = func(b []byte, v reflect.Value) int { PutInt16(b, int16(v.Int())); return n }
+	case reflect.Uint16:
+		c.put = func(b []byte, v reflect.Value) int { PutUint16(b, uint16(v.Uint())); return n }
+	case reflect.Int32:
+		c.put = func(b []byte, v reflect.Value) int { PutInt32(b, int32(v.Int())); return n }
+	case reflect.Uint32:
+		c.put = func(b []byte, v reflect.Value) int { PutUint32(b, uint32(v.Uint())); return n }
+	case reflect.Int64:
+		c.put = func(b []byte, v reflect.Value) int { PutInt64(b, v.Int()); return n }
+	case reflect.Uint64:
+		c.put = func(b []byte, v reflect.Value) int { PutUint64(b, v.Uint()); return n }
+	case reflect.Float32:
+		c.put = func(b []byte, v reflect.Value) int { PutFloat32(b, float32(v.Float())); return n }
+	case reflect.Float64:
+		c.put = func(b []byte, v reflect.Value) int { PutFloat64(b, v.Float()); return n }
+	case reflect.Complex64:
+		c.put = func(b []byte, v reflect.Value) int { PutComplex64(b, complex64(v.Complex())); return n }
+	case reflect.Complex128:
+		c.put = func(b []byte, v reflect.Value) int { PutComplex128(b, v.Complex()); return n }
+	default:
+		return
+	}
+	if k == reflect.Int || k == reflect.Uint {
+		n = 8
+	}
+	c.size = func(reflect.Value) int { return n }
+	c.get = func(b []byte, v reflect.Value, mask byte) (int, error) {
+		if len(b) < n {
+			return 0, ErrShortBuffer
+		}
+		var t [16]byte
+		b = masked(t[:n], b, mask)
+		switch k {
+		case reflect.Bool:
+			v.SetBool(Bool(b))
+		case reflect.Int:
+			v.SetInt(int64(Int(b)))
+		case reflect.Uint:
+			v.SetUint(uint64(Uint(b)))
+		case reflect.Int8:
+			v.SetInt(int64(Int8(b)))
+		case reflect.Uint8:
+			v.SetUint(uint64(Uint8(b)))
+		case reflect.Int16:
+			v.SetInt(int64(Int16(b)))
+		case reflect.Uint16:
+			v.SetUint(uint64(Uint16(b)))
+		case reflect.Int32:
+			v.SetInt(int64(Int32(b)))
+		case reflect.Uint32:
+			v.SetUint(uint64(Uint32(b)))
+		case reflect.Int64:
+			v.SetInt(Int64(b))
+		case reflect.Uint64:
+			v.SetUint(Uint64(b))
+		case reflect.Float32:
+			v.SetFloat(float64(Float32(b)))
+		case reflect.Float64:
+			v.SetFloat(Float64(b))
+		case reflect.Complex64:
+			v.SetComplex(complex128(Complex64(b)))
+		case reflect.Complex128:
+			v.SetComplex(Complex128(b))
+		}
+		return n, nil
+	}
+}
@@ -0,0 +1,231 @@
+package lex
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+//Decimal is an exact decimal value, equal to Unscaled × 10^-Scale.
+//A nil Unscaled is treated as zero.
+type Decimal struct {
+	Unscaled *big.Int
+	Scale    int32
+}
+
+//ParseDecimal parses a decimal string such as "-123.4500" or "1.2E+3", as formatted by String.
+//The scale of the result is the number of digits after the decimal point, less any exponent.
+func ParseDecimal(s string) (Decimal, error) {
+	digits := strings.TrimLeft(s, "+-")
+	if len(s)-len(digits) > 1 {
+		return Decimal{}, errors.New("lex.ParseDecimal: invalid")
+	}
+
+	var scale int64
+	if i := strings.IndexAny(digits, "eE"); i >= 0 {
+		exp, err := strconv.ParseInt(digits[i+1:], 10, 64)
+		if err != nil {
+			return Decimal{}, errors.New("lex.ParseDecimal: invalid")
+		}
+		scale = -exp
+		digits = digits[:i]
+	}
+	if i := strings.IndexByte(digits, '.'); i >= 0 {
+		scale += int64(len(digits) - i - 1)
+		digits = digits[:i] + digits[i+1:]
+	}
+	if digits == "" || strings.IndexFunc(digits, func(r rune) bool { return r < '0' || r > '9' }) >= 0 {
+		return Decimal{}, errors.New("lex.ParseDecimal: invalid")
+	}
+	if scale < math.MinInt32 || scale > math.MaxInt32 {
+		return Decimal{}, errors.New("lex.ParseDecimal: invalid")
+	}
+
+	u, _ := new(big.Int).SetString(digits, 10)
+	if strings.HasPrefix(s, "-") {
+		u.Neg(u)
+	}
+	return Decimal{u, int32(scale)}, nil
+}
+
+//String formats d as a decimal string, with Scale digits after the decimal point.
+//A negative Scale, or one needing more than six zeros after the decimal point before the first digit, is formatted
+//with an exponent instead, as in "1.2E+3", so that the string never has more zeros than are needed to show Unscaled.
+func (d Decimal) String() string {
+	u := d.Unscaled
+	if u == nil {
+		u = new(big.Int)
+	}
+
+	digits := new(big.Int).Abs(u).String()
+	adjusted := int64(len(digits)) - 1 - int64(d.Scale)
+	switch {
+	case d.Scale < 0 || adjusted < -6:
+		exp := strconv.FormatInt(adjusted, 10)
+		if adjusted >= 0 {
+			exp = "+" + exp
+		}
+		if len(digits) > 1 {
+			digits = digits[:1] + "." + digits[1:]
+		}
+		digits += "E" + exp
+	case d.Scale > 0:
+		if n := int(d.Scale) + 1 - len(digits); n > 0 {
+			digits = strings.Repeat("0", n) + digits
+		}
+		i := len(digits) - int(d.Scale)
+		digits = digits[:i] + "." + digits[i:]
+	}
+	if u.Sign() < 0 {
+		return "-" + digits
+	}
+	return digits
+}
+
+//Each Decimal is encoded as a sign marker, followed by the exponent and mantissa of its absolute value,
+//such that the value is 0.M × 100^E, where M is a sequence of base-100 digits with no trailing zeros.
+//Each digit X is encoded as 2X+1, except for the last, which is encoded as 2X.
+//Negative values invert the exponent and mantissa, so that larger magnitudes sort first.
+const (
+	decNeg  = 0x00
+	decZero = 0x01
+	decPos  = 0x02
+)
+
+//centimal returns the sign, exponent and base-100 mantissa of d, normalized as described above.
+func centimal(d Decimal) (int, int32, []byte) {
+	if d.Unscaled == nil || d.Unscaled.Sign() == 0 {
+		return 0, 0, nil
+	}
+
+	digits := new(big.Int).Abs(d.Unscaled).String()
+	e := int64(len(digits)) - int64(d.Scale)
+	digits = strings.TrimRight(digits, "0")
+	if e%2 != 0 {
+		digits = "0" + digits
+		e++
+	}
+	if len(digits)%2 != 0 {
+		digits += "0"
+	}
+
+	m := make([]byte, len(digits)/2)
+	for i := range m {
+		m[i] = (digits[2*i]-'0')*10 + digits[2*i+1] - '0'
+	}
+	return d.Unscaled.Sign(), int32(e / 2), m
+}
+
+//DecimalSize returns the number of bytes PutDecimal would generate to encode d.
+func DecimalSize(d Decimal) int {
+	sign, _, m := centimal(d)
+	if sign == 0 {
+		return 1
+	}
+	return 5 + len(m)
+}
+
+//PutDecimal serializes Decimal as DecimalSize(d) bytes.
+//Order is preserved regardless of scale, so equal values such as 1.5 and 1.50 have identical encodings.
+//
+//The value is normalized to the form 0.M × 100^E, in the style of SQLite4.
+//A sign marker is followed by E, as per PutInt32, and then by each base-100 digit X of M encoded as 2X+1, except for the last, which is encoded as 2X.
+//For negative values, E and M are inverted.
+func PutDecimal(b []byte, d Decimal) {
+	sign, e, m := centimal(d)
+	switch sign {
+	case 0:
+		b[0] = decZero
+		return
+	case 1:
+		b[0] = decPos
+	case -1:
+		b[0] = decNeg
+	}
+
+	PutInt32(b[1:5], e)
+	for i, x := range m {
+		b[5+i] = 2*x + 1
+	}
+	b[4+len(m)]--
+	if sign < 0 {
+		invert(b[1 : 5+len(m)])
+	}
+}
+
+//ScanDecimal deserializes Decimal from byte slice, returning the value and the number of bytes read.
+//Assumes that other values may be stored after the encoded value.
+//The value is returned with the smallest scale that represents it exactly, so 1.50 is returned as 1.5,
+//and 1000 as 1 with a scale of -3.
+//If b does not begin with a valid decimal, ScanDecimal returns a zero Decimal and -1.
+func ScanDecimal(b []byte) (Decimal, int) {
+	d, n, err := readDecimal(b, 0)
+	if err != nil {
+		return Decimal{}, -1
+	}
+	return d, n
+}
+
+//readDecimal is as per ScanDecimal, returning ErrShortBuffer if b ends within the decimal, or ErrInvalid if b does not begin with a valid decimal.
+//Each byte of b is first combined with mask, so that a mask of 0xFF decodes a descending value.
+func readDecimal(b []byte, mask byte) (Decimal, int, error) {
+	if len(b) == 0 {
+		return Decimal{}, 0, ErrShortBuffer
+	}
+
+	neg := false
+	switch b[0] ^ mask {
+	case decZero:
+		return Decimal{new(big.Int), 0}, 1, nil
+	case decPos:
+	case decNeg:
+		neg = true
+		mask ^= 0xFF
+	default:
+		return Decimal{}, 0, ErrInvalid
+	}
+	if len(b) < 6 {
+		return Decimal{}, 0, ErrShortBuffer
+	}
+
+	var t [4]byte
+	for i := range t {
+		t[i] = b[1+i] ^ mask
+	}
+	e := int64(Int32(t[:]))
+
+	digits := make([]byte, 0, 2*len(b))
+	n := 5
+	for ; ; n++ {
+		if n >= len(b) {
+			return Decimal{}, 0, ErrShortBuffer
+		}
+		x := b[n] ^ mask
+		if x>>1 > 99 {
+			return Decimal{}, 0, ErrInvalid
+		}
+		digits = append(digits, '0'+(x>>1)/10, '0'+(x>>1)%10)
+		if x&1 == 0 {
+			break
+		}
+	}
+	n++
+
+	//0.M × 100^E, with M having len(digits) decimal digits, of which trailing zeros are folded into the scale
+	s := strings.TrimRight(string(digits), "0")
+	scale := int64(len(s)) - 2*e
+	if scale < math.MinInt32 || scale > math.MaxInt32 {
+		return Decimal{}, 0, ErrInvalid
+	}
+
+	u, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return Decimal{}, 0, ErrInvalid
+	}
+	if neg {
+		u.Neg(u)
+	}
+	return Decimal{u, int32(scale)}, n, nil
+}
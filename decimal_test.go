@@ -0,0 +1,188 @@
+package lex
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+	"testing/quick"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func decimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func TestDecimal(t *testing.T) {
+	r := []string{
+		"-10000000000000000000000000000000000000000", "-12345678901234567890.5", "-100", "-99.99", "-10", "-1.01", "-1", "-0.999", "-0.5", "-0.05", "-0.0001",
+		"0",
+		"0.0001", "0.000100001", "0.05", "0.5", "0.999", "1", "1.01", "1.1", "10", "99.99", "100", "12345678901234567890.5", "10000000000000000000000000000000000000000",
+	}
+	var prev []byte
+	for _, s := range r {
+		v := decimal(s)
+		b := make([]byte, DecimalSize(v))
+		PutDecimal(b, v)
+
+		v1, n := ScanDecimal(b)
+		assert.Equal(t, len(b), n, "%v", s)
+		assert.Equal(t, 0, rat(v).Cmp(rat(v1)), "%v %v", s, v1)
+
+		if prev != nil {
+			assert.Equal(t, -1, bytes.Compare(prev, b), "%v", s)
+		}
+		prev = b
+	}
+}
+
+func pow10(scale int32) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale)), nil)
+}
+
+func rat(d Decimal) *big.Rat {
+	r := new(big.Rat).SetInt(d.Unscaled)
+	if d.Scale >= 0 {
+		return r.Quo(r, new(big.Rat).SetInt(pow10(d.Scale)))
+	}
+	return r.Mul(r, new(big.Rat).SetInt(pow10(-d.Scale)))
+}
+
+func TestDecimal_scale(t *testing.T) {
+	var tests = []struct {
+		in  []Decimal
+		out string
+	}{
+		{[]Decimal{decimal("1.5"), decimal("1.50"), decimal("01.500"), {big.NewInt(15), 1}}, "1.5"},
+		{[]Decimal{decimal("1000"), decimal("1000.0"), {big.NewInt(1), -3}, {big.NewInt(10), -2}}, "1E+3"},
+		{[]Decimal{decimal("0"), decimal("-0.000"), {nil, 5}, {big.NewInt(0), -5}}, "0"},
+		{[]Decimal{decimal("-0.05"), {big.NewInt(-500), 4}}, "-0.05"},
+	}
+	for _, tt := range tests {
+		var expected []byte
+		for _, v := range tt.in {
+			b := make([]byte, DecimalSize(v))
+			PutDecimal(b, v)
+			if expected != nil {
+				assert.Equal(t, expected, b, "%v", v)
+			}
+			expected = b
+
+			v1, _ := ScanDecimal(b)
+			assert.Equal(t, tt.out, v1.String())
+		}
+	}
+}
+
+func TestDecimal_format(t *testing.T) {
+	b := make([]byte, 7)
+	PutDecimal(b, decimal("1.5")) //0.0150 × 100^1
+	assert.Equal(t, []byte{0x02, 0x80, 0x00, 0x00, 0x01, 0x03, 0x64}, b)
+
+	PutDecimal(b, decimal("-1.5"))
+	assert.Equal(t, []byte{0x00, 0x7F, 0xFF, 0xFF, 0xFE, 0xFC, 0x9B}, b)
+}
+
+func TestDecimal_RandomCompare(t *testing.T) {
+	f := func(u1, u2 int64, s1, s2 int8) bool {
+		a1 := Decimal{big.NewInt(u1), int32(s1)}
+		a2 := Decimal{big.NewInt(u2), int32(s2)}
+
+		b1 := make([]byte, DecimalSize(a1)+1)
+		PutDecimal(b1, a1)
+		b1[len(b1)-1] = 0xFF //trailing data must not affect order
+
+		b2 := make([]byte, DecimalSize(a2))
+		PutDecimal(b2, a2)
+
+		expected := rat(a1).Cmp(rat(a2))
+		if expected == 0 {
+			expected = 1
+		}
+		_, n := ScanDecimal(b1)
+		return bytes.Compare(b1, b2) == expected && n == len(b1)-1
+	}
+	assert.Nil(t, quick.Check(f, nil))
+}
+
+func TestParseDecimal(t *testing.T) {
+	var tests = []struct {
+		in       string
+		unscaled int64
+		scale    int32
+	}{
+		{"0", 0, 0},
+		{"-123.4500", -1234500, 4},
+		{"+1.", 1, 0},
+		{".5", 5, 1},
+		{"-.05", -5, 2},
+		{"1e5", 1, -5},
+		{"-1.2E+3", -12, -2},
+		{"1.5E-3", 15, 4},
+	}
+	for _, tt := range tests {
+		d, err := ParseDecimal(tt.in)
+		assert.Nil(t, err)
+		assert.Equal(t, tt.unscaled, d.Unscaled.Int64())
+		assert.Equal(t, tt.scale, d.Scale)
+	}
+
+	for _, s := range []string{"", "-", ".", "+-1", "1.2.3", "e5", "1e", "1e+", "1e5.0", "1e99999999999", "abc", " 1"} {
+		_, err := ParseDecimal(s)
+		assert.NotNil(t, err, "%q", s)
+	}
+}
+
+func TestDecimal_String(t *testing.T) {
+	var tests = []struct {
+		in       Decimal
+		expected string
+	}{
+		{Decimal{}, "0"},
+		{Decimal{big.NewInt(-1234500), 4}, "-123.4500"},
+		{Decimal{big.NewInt(5), 3}, "0.005"},
+		{Decimal{big.NewInt(-5), 1}, "-0.5"},
+		{Decimal{big.NewInt(12), -2}, "1.2E+3"},
+		{Decimal{big.NewInt(1), -3}, "1E+3"},
+		{Decimal{big.NewInt(-15), 8}, "-1.5E-7"},
+		{Decimal{big.NewInt(15), 7}, "0.0000015"},
+		{Decimal{big.NewInt(1), -2147483648}, "1E+2147483648"},
+		{Decimal{big.NewInt(1), 2147483647}, "1E-2147483647"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, tt.in.String())
+
+		d, err := ParseDecimal(tt.expected)
+		assert.Nil(t, err, "%v", tt.expected)
+		assert.Equal(t, tt.expected, d.String())
+	}
+}
+
+func TestScanDecimal_exponent(t *testing.T) {
+	//a large exponent is kept in the scale, rather than expanded into zeros
+	d, n := ScanDecimal([]byte{0x02, 0xC0, 0x00, 0x00, 0x00, 0x02})
+	assert.Equal(t, 6, n)
+	assert.Equal(t, int64(1), d.Unscaled.Int64()) //0.01 × 100^(2^30)
+	assert.Equal(t, int32(-2147483646), d.Scale)
+	assert.Equal(t, "1E+2147483646", d.String())
+}
+
+func TestScanDecimal_badformat(t *testing.T) {
+	var tests = [][]byte{
+		nil,
+		{0x03},
+		{0x02, 0x80, 0x00, 0x00, 0x01},       //no mantissa
+		{0x02, 0x80, 0x00, 0x00, 0x01, 0x03}, //unterminated mantissa
+		{0x02, 0x80, 0x00, 0x00, 0x01, 0xF0}, //digit out of range
+		{0x02, 0xFF, 0xFF, 0xFF, 0xFF, 0x02}, //scale out of range
+		{0x02, 0x00, 0x00, 0x00, 0x00, 0x02}, //scale out of range
+	}
+	for _, b := range tests {
+		_, n := ScanDecimal(b)
+		assert.Equal(t, -1, n, "%v", b)
+	}
+}
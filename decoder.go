@@ -0,0 +1,250 @@
+package lex
+
+import (
+	"errors"
+	"math/big"
+	"reflect"
+	"time"
+)
+
+//Decoder reads the values of a composite key in turn, as written by Key, advancing past each value as it is read.
+//
+//Errors are sticky: once a read fails, it and all subsequent reads return zero values, and Err reports the first error.
+//This allows a key to be decoded without checking each value, in the manner of bufio.Scanner:
+//
+//	d := lex.NewDecoder(k)
+//	id, score := d.Int16(), d.Float32()
+//	if err := d.Err(); err != nil {
+//		...
+//	}
+type Decoder struct {
+	b   []byte
+	err error
+}
+
+//NewDecoder returns a Decoder reading from the start of b.
+func NewDecoder(b []byte) *Decoder {
+	return &Decoder{b: b}
+}
+
+//Err returns the first error encountered while reading, or nil.
+func (d *Decoder) Err() error {
+	return d.err
+}
+
+//Remaining returns the bytes not yet read, or nil once an error has been encountered.
+func (d *Decoder) Remaining() []byte {
+	return d.b
+}
+
+//advance moves past the n bytes just read, or records err.
+//After an error nothing remains to be read, so every later read also fails, and its error is discarded.
+func (d *Decoder) advance(n int, err error) {
+	if err != nil {
+		if d.err == nil {
+			d.err = err
+		}
+		d.b = nil
+		return
+	}
+	d.b = d.b[n:]
+}
+
+//Bool reads a bool, as per ReadBool.
+func (d *Decoder) Bool() bool {
+	v, n, err := ReadBool(d.b)
+	d.advance(n, err)
+	return v
+}
+
+//Uint8 reads a uint8, as per ReadUint8.
+func (d *Decoder) Uint8() uint8 {
+	v, n, err := ReadUint8(d.b)
+	d.advance(n, err)
+	return v
+}
+
+//Uint16 reads a uint16, as per ReadUint16.
+func (d *Decoder) Uint16() uint16 {
+	v, n, err := ReadUint16(d.b)
+	d.advance(n, err)
+	return v
+}
+
+//Uint32 reads a uint32, as per ReadUint32.
+func (d *Decoder) Uint32() uint32 {
+	v, n, err := ReadUint32(d.b)
+	d.advance(n, err)
+	return v
+}
+
+//Uint64 reads a uint64, as per ReadUint64.
+func (d *Decoder) Uint64() uint64 {
+	v, n, err := ReadUint64(d.b)
+	d.advance(n, err)
+	return v
+}
+
+//Int8 reads an int8, as per ReadInt8.
+func (d *Decoder) Int8() int8 {
+	v, n, err := ReadInt8(d.b)
+	d.advance(n, err)
+	return v
+}
+
+//Int16 reads an int16, as per ReadInt16.
+func (d *Decoder) Int16() int16 {
+	v, n, err := ReadInt16(d.b)
+	d.advance(n, err)
+	return v
+}
+
+//Int32 reads an int32, as per ReadInt32.
+func (d *Decoder) Int32() int32 {
+	v, n, err := ReadInt32(d.b)
+	d.advance(n, err)
+	return v
+}
+
+//Int64 reads an int64, as per ReadInt64.
+func (d *Decoder) Int64() int64 {
+	v, n, err := ReadInt64(d.b)
+	d.advance(n, err)
+	return v
+}
+
+//Float32 reads a float32, as per ReadFloat32.
+func (d *Decoder) Float32() float32 {
+	v, n, err := ReadFloat32(d.b)
+	d.advance(n, err)
+	return v
+}
+
+//Float64 reads a float64, as per ReadFloat64.
+func (d *Decoder) Float64() float64 {
+	v, n, err := ReadFloat64(d.b)
+	d.advance(n, err)
+	return v
+}
+
+//Complex64 reads a complex64, as per ReadComplex64.
+func (d *Decoder) Complex64() complex64 {
+	v, n, err := ReadComplex64(d.b)
+	d.advance(n, err)
+	return v
+}
+
+//Complex128 reads a complex128, as per ReadComplex128.
+func (d *Decoder) Complex128() complex128 {
+	v, n, err := ReadComplex128(d.b)
+	d.advance(n, err)
+	return v
+}
+
+//Byte reads a byte, as per ReadByte.
+func (d *Decoder) Byte() byte {
+	v, n, err := ReadByte(d.b)
+	d.advance(n, err)
+	return v
+}
+
+//Rune reads a rune, as per ReadRune.
+func (d *Decoder) Rune() rune {
+	v, n, err := ReadRune(d.b)
+	d.advance(n, err)
+	return v
+}
+
+//Uint reads a uint, as per ReadUint.
+func (d *Decoder) Uint() uint {
+	v, n, err := ReadUint(d.b)
+	d.advance(n, err)
+	return v
+}
+
+//Int reads an int, as per ReadInt.
+func (d *Decoder) Int() int {
+	v, n, err := ReadInt(d.b)
+	d.advance(n, err)
+	return v
+}
+
+//String reads a NUL-terminated string, as per ReadString.
+//Note that as Decoder therefore implements fmt.Stringer, printing a Decoder consumes a value.
+func (d *Decoder) String() string {
+	v, n, err := ReadString(d.b)
+	d.advance(n, err)
+	return v
+}
+
+//EscapedString reads an escaped string, as written for values wrapped with Escaped, as per ReadEscapedString.
+func (d *Decoder) EscapedString() string {
+	v, n, err := ReadEscapedString(d.b)
+	d.advance(n, err)
+	return v
+}
+
+//Bytes reads an escaped []byte, as per ReadEscapedBytes.
+//The value is always a copy, and never shares memory with the key.
+func (d *Decoder) Bytes() []byte {
+	v, n, err := ReadEscapedBytes(d.b)
+	d.advance(n, err)
+	return v
+}
+
+//Uvarint reads a uint64, as written for values wrapped with Varint, as per ReadUvarint.
+func (d *Decoder) Uvarint() uint64 {
+	v, n, err := ReadUvarint(d.b)
+	d.advance(n, err)
+	return v
+}
+
+//Varint reads an int64, as written for values wrapped with Varint, as per ReadVarint.
+func (d *Decoder) Varint() int64 {
+	v, n, err := ReadVarint(d.b)
+	d.advance(n, err)
+	return v
+}
+
+//Number reads a number, as written for values wrapped with Number, as per ReadNumber.
+func (d *Decoder) Number() interface{} {
+	v, n, err := ReadNumber(d.b)
+	d.advance(n, err)
+	return v
+}
+
+//Time reads a time.Time at nanosecond precision, as per ReadTime.
+func (d *Decoder) Time() time.Time {
+	v, n, err := ReadTime(d.b, time.Nanosecond)
+	d.advance(n, err)
+	return v
+}
+
+//BigInt reads a *big.Int, as per ReadBigInt.
+func (d *Decoder) BigInt() *big.Int {
+	v, n, err := ReadBigInt(d.b)
+	d.advance(n, err)
+	return v
+}
+
+//Decimal reads a Decimal, as per ReadDecimal.
+func (d *Decoder) Decimal() Decimal {
+	v, n, err := ReadDecimal(d.b)
+	d.advance(n, err)
+	return v
+}
+
+//Reflect reads a value of any type into data, as per Reflect, except that a string must be terminated.
+//Data may be wrapped with options such as Desc, to read a value written with the same options.
+func (d *Decoder) Reflect(data interface{}) {
+	if d.err != nil {
+		return
+	}
+	data, o := unwrap(data)
+	v := reflect.ValueOf(data)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		d.advance(0, errors.New("lex.Decoder.Reflect: invalid (data must be a pointer)"))
+		return
+	}
+	d.advance(_reflect(d.b, v.Elem(), o))
+}
@@ -0,0 +1,114 @@
+package lex_test
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/xcdb/lex"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecoder(t *testing.T) {
+	tm := time.Unix(1, 2).UTC()
+	dec, _ := lex.ParseDecimal("-1.25")
+	k := lex.MustKey(true, uint8(1), uint16(2), uint32(3), uint64(4), int8(-1), int16(-2), int32(-3), int64(-4),
+		float32(1.5), 2.5, complex64(1+2i), 3+4i, byte(5), 'x', uint(6), -7,
+		"a", lex.Escaped("b\x00"), []byte{0, 1}, lex.Varint(uint64(300)), lex.Varint(int64(-300)), lex.Number(42),
+		tm, big.NewInt(-8), dec, lex.Desc(int16(9)))
+
+	d := lex.NewDecoder(k)
+	assert.Equal(t, true, d.Bool())
+	assert.Equal(t, uint8(1), d.Uint8())
+	assert.Equal(t, uint16(2), d.Uint16())
+	assert.Equal(t, uint32(3), d.Uint32())
+	assert.Equal(t, uint64(4), d.Uint64())
+	assert.Equal(t, int8(-1), d.Int8())
+	assert.Equal(t, int16(-2), d.Int16())
+	assert.Equal(t, int32(-3), d.Int32())
+	assert.Equal(t, int64(-4), d.Int64())
+	assert.Equal(t, float32(1.5), d.Float32())
+	assert.Equal(t, 2.5, d.Float64())
+	assert.Equal(t, complex64(1+2i), d.Complex64())
+	assert.Equal(t, 3+4i, d.Complex128())
+	assert.Equal(t, byte(5), d.Byte())
+	assert.Equal(t, 'x', d.Rune())
+	assert.Equal(t, uint(6), d.Uint())
+	assert.Equal(t, -7, d.Int())
+	assert.Equal(t, "a", d.String())
+	assert.Equal(t, "b\x00", d.EscapedString())
+	assert.Equal(t, []byte{0, 1}, d.Bytes())
+	assert.Equal(t, uint64(300), d.Uvarint())
+	assert.Equal(t, int64(-300), d.Varint())
+	assert.Equal(t, int64(42), d.Number())
+	assert.Equal(t, tm, d.Time())
+	assert.Equal(t, int64(-8), d.BigInt().Int64())
+	assert.Equal(t, "-1.25", d.Decimal().String())
+	var i int16
+	d.Reflect(lex.Desc(&i))
+	assert.Equal(t, int16(9), i)
+
+	assert.Nil(t, d.Err())
+	assert.Empty(t, d.Remaining())
+}
+
+func TestDecoder_remaining(t *testing.T) {
+	k := lex.MustKey(int16(1), "a", int32(2))
+	d := lex.NewDecoder(k)
+	assert.Equal(t, int16(1), d.Int16())
+	assert.Equal(t, k[2:], d.Remaining())
+	assert.Equal(t, "a", d.String())
+	assert.Equal(t, k[4:], d.Remaining())
+}
+
+func TestDecoder_struct(t *testing.T) {
+	k := lex.MustKey(testStruct{-1, "a", 2.5}, "b")
+	d := lex.NewDecoder(k)
+	var v testStruct
+	d.Reflect(&v)
+	assert.Equal(t, "b", d.String())
+	assert.Nil(t, d.Err())
+	assert.Equal(t, testStruct{-1, "a", 2.5}, v)
+}
+
+func TestDecoder_sticky(t *testing.T) {
+	k := lex.MustKey(int16(1), "a")
+	d := lex.NewDecoder(k)
+	assert.Equal(t, int16(1), d.Int16())
+	assert.Equal(t, int64(0), d.Int64()) //only 2 bytes remain
+	assert.Equal(t, lex.ErrShortBuffer, d.Err())
+	assert.Nil(t, d.Remaining())
+
+	//later reads fail without replacing the first error
+	assert.Equal(t, "", d.String())
+	assert.Equal(t, false, d.Bool())
+	var s string
+	d.Reflect(&s)
+	assert.Equal(t, "", s)
+	assert.Equal(t, lex.ErrShortBuffer, d.Err())
+}
+
+func TestDecoder_invalid(t *testing.T) {
+	d := lex.NewDecoder([]byte("unterminated"))
+	assert.Equal(t, "", d.String())
+	assert.Equal(t, lex.ErrMissingTerminator, d.Err())
+
+	d = lex.NewDecoder(lex.MustKey(1))
+	var i int
+	d.Reflect(i)
+	assert.NotNil(t, d.Err())
+}
+
+func ExampleDecoder() {
+	k := lex.MustKey(int16(42), float32(-2.5), "howdy")
+
+	d := lex.NewDecoder(k)
+	id, score, name := d.Int16(), d.Float32(), d.String()
+	if err := d.Err(); err != nil {
+		panic(err)
+	}
+	fmt.Println(id, score, name, len(d.Remaining()))
+	// Output: 42 -2.5 howdy 0
+}
@@ -0,0 +1,282 @@
+package lex
+
+//Descending variants invert each byte of the ascending encoding, which reverses the order of any
+//encoding in which no value is a prefix of another. Strings are NUL terminated, so the terminator
+//is inverted along with the value and a string sorts after any longer string that it is a prefix of.
+
+//invert replaces each byte of b with its bitwise complement.
+func invert(b []byte) {
+	for i := range b {
+		b[i] = ^b[i]
+	}
+}
+
+//masked returns b, or if mask is non-zero, as much of b as fits in dst, with each byte combined with mask.
+//A small array on the caller's stack can thus hold an inverted fixed-size value without allocating.
+func masked(dst, b []byte, mask byte) []byte {
+	if mask == 0 {
+		return b
+	}
+	n := copy(dst, b)
+	for i := range dst[:n] {
+		dst[i] ^= mask
+	}
+	return dst[:n]
+}
+
+//PutBoolDesc serializes bool as a single byte, in descending order.
+//True is encoded as 0xFE; false as 0xFF.
+func PutBoolDesc(b []byte, v bool) {
+	PutBool(b, v)
+	b[0] = ^b[0]
+}
+
+//BoolDesc deserializes bool from a single byte, in descending order.
+func BoolDesc(b []byte) bool {
+	return ^Byte(b) == 1
+}
+
+//PutUint8Desc serializes uint8 as a single byte, in descending order.
+func PutUint8Desc(b []byte, v uint8) {
+	PutUint8(b, ^v)
+}
+
+//Uint8Desc deserializes uint8 from a single byte, in descending order.
+func Uint8Desc(b []byte) uint8 {
+	return ^Uint8(b)
+}
+
+//PutUint16Desc serializes uint16 as 2 bytes, in descending order.
+func PutUint16Desc(b []byte, v uint16) {
+	PutUint16(b, ^v)
+}
+
+//Uint16Desc deserializes uint16 from 2 bytes, in descending order.
+func Uint16Desc(b []byte) uint16 {
+	return ^Uint16(b)
+}
+
+//PutUint32Desc serializes uint32 as 4 bytes, in descending order.
+func PutUint32Desc(b []byte, v uint32) {
+	PutUint32(b, ^v)
+}
+
+//Uint32Desc deserializes uint32 from 4 bytes, in descending order.
+func Uint32Desc(b []byte) uint32 {
+	return ^Uint32(b)
+}
+
+//PutUint64Desc serializes uint64 as 8 bytes, in descending order.
+func PutUint64Desc(b []byte, v uint64) {
+	PutUint64(b, ^v)
+}
+
+//Uint64Desc deserializes uint64 from 8 bytes, in descending order.
+func Uint64Desc(b []byte) uint64 {
+	return ^Uint64(b)
+}
+
+//PutInt8Desc serializes int8 as 1 byte, in descending order.
+func PutInt8Desc(b []byte, v int8) {
+	PutInt8(b, ^v)
+}
+
+//Int8Desc deserializes int8 from 1 byte, in descending order.
+func Int8Desc(b []byte) int8 {
+	return ^Int8(b)
+}
+
+//PutInt16Desc serializes int16 as 2 bytes, in descending order.
+func PutInt16Desc(b []byte, v int16) {
+	PutInt16(b, ^v)
+}
+
+//Int16Desc deserializes int16 from 2 bytes, in descending order.
+func Int16Desc(b []byte) int16 {
+	return ^Int16(b)
+}
+
+//PutInt32Desc serializes int32 as 4 bytes, in descending order.
+func PutInt32Desc(b []byte, v int32) {
+	PutInt32(b, ^v)
+}
+
+//Int32Desc deserializes int32 from 4 bytes, in descending order.
+func Int32Desc(b []byte) int32 {
+	return ^Int32(b)
+}
+
+//PutInt64Desc serializes int64 as 8 bytes, in descending order.
+func PutInt64Desc(b []byte, v int64) {
+	PutInt64(b, ^v)
+}
+
+//Int64Desc deserializes int64 from 8 bytes, in descending order.
+func Int64Desc(b []byte) int64 {
+	return ^Int64(b)
+}
+
+//PutFloat32Desc serializes float32 as 4 bytes, in descending order.
+//Behaviour is otherwise identical to PutFloat32, so NaN sorts before infinity.
+func PutFloat32Desc(b []byte, v float32) {
+	PutFloat32(b, v)
+	PutUint32(b, ^Uint32(b))
+}
+
+//Float32Desc deserializes float32 from 4 bytes, in descending order.
+func Float32Desc(b []byte) float32 {
+	var t [4]byte
+	PutUint32(t[:], ^Uint32(b))
+	return Float32(t[:])
+}
+
+//PutFloat64Desc serializes float64 as 8 bytes, in descending order.
+//Behaviour is otherwise identical to PutFloat64, so NaN sorts before infinity.
+func PutFloat64Desc(b []byte, v float64) {
+	PutFloat64(b, v)
+	PutUint64(b, ^Uint64(b))
+}
+
+//Float64Desc deserializes float64 from 8 bytes, in descending order.
+func Float64Desc(b []byte) float64 {
+	var t [8]byte
+	PutUint64(t[:], ^Uint64(b))
+	return Float64(t[:])
+}
+
+//PutComplex64Desc serializes complex64 as 8 bytes, in descending order.
+//Behaviour is identical to PutFloat32Desc(real) followed by PutFloat32Desc(imag).
+func PutComplex64Desc(b []byte, v complex64) {
+	r, i := real(v), imag(v)
+	PutFloat32Desc(b, r)
+	PutFloat32Desc(b[4:8], i)
+}
+
+//Complex64Desc deserializes complex64 from 8 bytes, in descending order.
+func Complex64Desc(b []byte) complex64 {
+	r := Float32Desc(b)
+	i := Float32Desc(b[4:8])
+	return complex(r, i)
+}
+
+//PutComplex128Desc serializes complex128 as 16 bytes, in descending order.
+//Behaviour is identical to PutFloat64Desc(real) followed by PutFloat64Desc(imag).
+func PutComplex128Desc(b []byte, v complex128) {
+	r, i := real(v), imag(v)
+	PutFloat64Desc(b, r)
+	PutFloat64Desc(b[8:16], i)
+}
+
+//Complex128Desc deserializes complex128 from 16 bytes, in descending order.
+func Complex128Desc(b []byte) complex128 {
+	r := Float64Desc(b)
+	i := Float64Desc(b[8:16])
+	return complex(r, i)
+}
+
+//PutByteDesc serializes a single byte, in descending order.
+func PutByteDesc(b []byte, v byte) {
+	b[0] = ^v
+}
+
+//ByteDesc deserializes a single byte, in descending order.
+func ByteDesc(b []byte) byte {
+	return ^b[0]
+}
+
+//PutRuneDesc serializes rune as 4 bytes, in descending order.
+//Behaviour is identical to PutInt32Desc.
+func PutRuneDesc(b []byte, v rune) {
+	PutInt32Desc(b, int32(v))
+}
+
+//RuneDesc deserializes rune from 4 bytes, in descending order.
+//Behaviour is identical to Int32Desc.
+func RuneDesc(b []byte) rune {
+	return rune(Int32Desc(b))
+}
+
+//PutUintDesc serializes uint as 8 bytes, in descending order, regardless of architecture.
+//Behaviour is identical to PutUint64Desc.
+func PutUintDesc(b []byte, v uint) {
+	PutUint64Desc(b, uint64(v))
+}
+
+//UintDesc deserializes uint from 8 bytes, in descending order, regardless of architecture.
+//Behaviour is identical to Uint64Desc.
+func UintDesc(b []byte) uint {
+	return uint(Uint64Desc(b))
+}
+
+//PutIntDesc serializes int as 8 bytes, in descending order, regardless of architecture.
+//Behaviour is identical to PutInt64Desc.
+func PutIntDesc(b []byte, v int) {
+	PutInt64Desc(b, int64(v))
+}
+
+//IntDesc deserializes int from 8 bytes, in descending order, regardless of architecture.
+//Behaviour is identical to Int64Desc.
+func IntDesc(b []byte) int {
+	return int(Int64Desc(b))
+}
+
+//PutStringDesc serializes string as len(v) + 1 bytes, in descending order.
+//Behaviour is identical to PutString, with each byte, including the terminator, inverted.
+func PutStringDesc(b []byte, v string) {
+	PutString(b, v)
+	invert(b[:len(v)+1])
+}
+
+//StringDesc deserializes string from byte slice, in descending order.
+//Assumes the whole slice represents the value to deserialize.
+func StringDesc(b []byte) string {
+	l := len(b) - 1
+	s := make([]byte, l)
+	for i := range s {
+		s[i] = ^b[i]
+	}
+	return string(s)
+}
+
+//ScanStringDesc deserializes string from byte slice, in descending order.
+//Assumes that other values may be stored after the encoded string value.
+//Prefer StringDesc if there are no other values stored in the slice.
+func ScanStringDesc(b []byte) string {
+	const nul = 0
+	for i := 0; i < len(b); i++ {
+		if b[i] == ^byte(nul) {
+			return StringDesc(b[:i+1])
+		}
+	}
+	return ""
+}
+
+//PutEscapedStringDesc serializes string as EscapedStringSize(v) bytes, in descending order.
+//Behaviour is identical to PutEscapedString, with each byte inverted.
+func PutEscapedStringDesc(b []byte, v string) {
+	PutEscapedString(b, v)
+	invert(b[:EscapedStringSize(v)])
+}
+
+//ScanEscapedStringDesc deserializes string from byte slice, in descending order, returning the value and the number of bytes read.
+//If b does not begin with a valid escaped string, ScanEscapedStringDesc returns "" and -1.
+func ScanEscapedStringDesc(b []byte) (string, int) {
+	v, n := unescape(b, 0xFF)
+	if n < 0 {
+		return "", -1
+	}
+	return string(v), n
+}
+
+//PutEscapedBytesDesc serializes []byte as EscapedBytesSize(v) bytes, in descending order.
+//Behaviour is identical to PutEscapedBytes, with each byte inverted.
+func PutEscapedBytesDesc(b []byte, v []byte) {
+	PutEscapedBytes(b, v)
+	invert(b[:EscapedBytesSize(v)])
+}
+
+//ScanEscapedBytesDesc deserializes []byte from byte slice, in descending order, returning the value and the number of bytes read.
+//If b does not begin with a valid escaped value, ScanEscapedBytesDesc returns nil and -1.
+func ScanEscapedBytesDesc(b []byte) ([]byte, int) {
+	return unescape(b, 0xFF)
+}
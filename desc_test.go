@@ -0,0 +1,283 @@
+package lex
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+	"testing/quick"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBoolDesc(t *testing.T) {
+	b1 := make([]byte, 1)
+	PutBoolDesc(b1, true)
+	assert.True(t, BoolDesc(b1))
+
+	b2 := make([]byte, 1)
+	PutBoolDesc(b2, false)
+	assert.False(t, BoolDesc(b2))
+
+	assert.Equal(t, -1, bytes.Compare(b1, b2))
+}
+
+func TestUint8Desc_Range(t *testing.T) {
+	var prev []byte
+	for i := 0; i <= math.MaxUint8; i++ {
+		v := uint8(i)
+
+		b := make([]byte, 1)
+		PutUint8Desc(b, v)
+		assert.Equal(t, v, Uint8Desc(b))
+
+		if prev != nil {
+			assert.Equal(t, 1, bytes.Compare(prev, b))
+		}
+		prev = b
+	}
+}
+
+func TestInt8Desc_Range(t *testing.T) {
+	var prev []byte
+	for i := math.MinInt8; i <= math.MaxInt8; i++ {
+		v := int8(i)
+
+		b := make([]byte, 1)
+		PutInt8Desc(b, v)
+		assert.Equal(t, v, Int8Desc(b))
+
+		if prev != nil {
+			assert.Equal(t, 1, bytes.Compare(prev, b))
+		}
+		prev = b
+	}
+}
+
+func TestUint64Desc_RandomCompare(t *testing.T) {
+	f := func(a1, a2 uint64) bool {
+		b1 := make([]byte, 8)
+		PutUint64Desc(b1, a1)
+
+		b2 := make([]byte, 8)
+		PutUint64Desc(b2, a2)
+
+		var expected int
+		switch {
+		case a1 < a2:
+			expected = +1
+		case a1 > a2:
+			expected = -1
+		}
+		return bytes.Compare(b1, b2) == expected && Uint64Desc(b1) == a1
+	}
+	assert.Nil(t, quick.Check(f, nil))
+}
+
+func TestInt64Desc_RandomCompare(t *testing.T) {
+	f := func(a1, a2 int64) bool {
+		b1 := make([]byte, 8)
+		PutInt64Desc(b1, a1)
+
+		b2 := make([]byte, 8)
+		PutInt64Desc(b2, a2)
+
+		var expected int
+		switch {
+		case a1 < a2:
+			expected = +1
+		case a1 > a2:
+			expected = -1
+		}
+		return bytes.Compare(b1, b2) == expected && Int64Desc(b1) == a1
+	}
+	assert.Nil(t, quick.Check(f, nil))
+}
+
+func TestFloat64Desc_RandomCompare(t *testing.T) {
+	f := func(a1, a2 float64) bool {
+		if math.IsNaN(a1) || math.IsNaN(a2) {
+			return true //skip if NaN
+		}
+
+		b1 := make([]byte, 8)
+		PutFloat64Desc(b1, a1)
+
+		b2 := make([]byte, 8)
+		PutFloat64Desc(b2, a2)
+
+		var expected int
+		switch {
+		case a1 < a2:
+			expected = +1
+		case a1 > a2:
+			expected = -1
+		}
+		return bytes.Compare(b1, b2) == expected && Float64Desc(b1) == a1
+	}
+	assert.Nil(t, quick.Check(f, nil))
+}
+
+func TestFloat32Desc_RandomCompare(t *testing.T) {
+	f := func(a1, a2 float32) bool {
+		if math.IsNaN(float64(a1)) || math.IsNaN(float64(a2)) {
+			return true //skip if NaN
+		}
+
+		b1 := make([]byte, 4)
+		PutFloat32Desc(b1, a1)
+
+		b2 := make([]byte, 4)
+		PutFloat32Desc(b2, a2)
+
+		var expected int
+		switch {
+		case a1 < a2:
+			expected = +1
+		case a1 > a2:
+			expected = -1
+		}
+		return bytes.Compare(b1, b2) == expected && Float32Desc(b1) == a1
+	}
+	assert.Nil(t, quick.Check(f, nil))
+}
+
+func TestDesc_inverted(t *testing.T) {
+	//every descending encoding is the bytewise inverse of the ascending one
+	var tests = []struct {
+		asc, desc func(b []byte)
+		n         int
+	}{
+		{func(b []byte) { PutBool(b, true) }, func(b []byte) { PutBoolDesc(b, true) }, 1},
+		{func(b []byte) { PutUint8(b, 42) }, func(b []byte) { PutUint8Desc(b, 42) }, 1},
+		{func(b []byte) { PutUint16(b, 42) }, func(b []byte) { PutUint16Desc(b, 42) }, 2},
+		{func(b []byte) { PutUint32(b, 42) }, func(b []byte) { PutUint32Desc(b, 42) }, 4},
+		{func(b []byte) { PutUint64(b, 42) }, func(b []byte) { PutUint64Desc(b, 42) }, 8},
+		{func(b []byte) { PutInt8(b, -42) }, func(b []byte) { PutInt8Desc(b, -42) }, 1},
+		{func(b []byte) { PutInt16(b, -42) }, func(b []byte) { PutInt16Desc(b, -42) }, 2},
+		{func(b []byte) { PutInt32(b, -42) }, func(b []byte) { PutInt32Desc(b, -42) }, 4},
+		{func(b []byte) { PutInt64(b, -42) }, func(b []byte) { PutInt64Desc(b, -42) }, 8},
+		{func(b []byte) { PutFloat32(b, -4.2) }, func(b []byte) { PutFloat32Desc(b, -4.2) }, 4},
+		{func(b []byte) { PutFloat64(b, -4.2) }, func(b []byte) { PutFloat64Desc(b, -4.2) }, 8},
+		{func(b []byte) { PutComplex64(b, 1+2i) }, func(b []byte) { PutComplex64Desc(b, 1+2i) }, 8},
+		{func(b []byte) { PutComplex128(b, 1+2i) }, func(b []byte) { PutComplex128Desc(b, 1+2i) }, 16},
+		{func(b []byte) { PutByte(b, 42) }, func(b []byte) { PutByteDesc(b, 42) }, 1},
+		{func(b []byte) { PutRune(b, 'x') }, func(b []byte) { PutRuneDesc(b, 'x') }, 4},
+		{func(b []byte) { PutUint(b, 42) }, func(b []byte) { PutUintDesc(b, 42) }, 8},
+		{func(b []byte) { PutInt(b, -42) }, func(b []byte) { PutIntDesc(b, -42) }, 8},
+		{func(b []byte) { PutString(b, "howdy") }, func(b []byte) { PutStringDesc(b, "howdy") }, 6},
+		{func(b []byte) { PutEscapedString(b, "how\x00dy") }, func(b []byte) { PutEscapedStringDesc(b, "how\x00dy") }, 9},
+		{func(b []byte) { PutEscapedBytes(b, []byte{0}) }, func(b []byte) { PutEscapedBytesDesc(b, []byte{0}) }, 4},
+	}
+	for i, tt := range tests {
+		b1 := make([]byte, tt.n)
+		tt.asc(b1)
+		invert(b1)
+
+		b2 := make([]byte, tt.n)
+		tt.desc(b2)
+
+		assert.Equal(t, b1, b2, "%v", i)
+	}
+}
+
+func TestDesc_roundtrip(t *testing.T) {
+	b := make([]byte, 16)
+
+	PutUint16Desc(b, 42)
+	assert.Equal(t, uint16(42), Uint16Desc(b))
+	PutUint32Desc(b, 42)
+	assert.Equal(t, uint32(42), Uint32Desc(b))
+	PutInt16Desc(b, -42)
+	assert.Equal(t, int16(-42), Int16Desc(b))
+	PutInt32Desc(b, -42)
+	assert.Equal(t, int32(-42), Int32Desc(b))
+	PutComplex64Desc(b, 1+2i)
+	assert.Equal(t, complex64(1+2i), Complex64Desc(b))
+	PutComplex128Desc(b, 1+2i)
+	assert.Equal(t, complex128(1+2i), Complex128Desc(b))
+	PutByteDesc(b, 42)
+	assert.Equal(t, byte(42), ByteDesc(b))
+	PutRuneDesc(b, 'x')
+	assert.Equal(t, 'x', RuneDesc(b))
+	PutUintDesc(b, 42)
+	assert.Equal(t, uint(42), UintDesc(b))
+	PutIntDesc(b, -42)
+	assert.Equal(t, -42, IntDesc(b))
+}
+
+func TestDesc_ZeroAllocs(t *testing.T) {
+	b := make([]byte, 8)
+	assert.Zero(t, testing.AllocsPerRun(1, func() { PutInt64Desc(b, 42) }))
+	assert.Zero(t, testing.AllocsPerRun(1, func() { Int64Desc(b) }))
+	assert.Zero(t, testing.AllocsPerRun(1, func() { PutFloat64Desc(b, 42) }))
+	assert.Zero(t, testing.AllocsPerRun(1, func() { Float64Desc(b) }))
+}
+
+//
+
+func TestStringDesc(t *testing.T) {
+	r := []string{"b", "ab", "a", ""}
+	var prev []byte
+	for _, v := range r {
+		b := make([]byte, len(v)+1)
+		PutStringDesc(b, v)
+		assert.Equal(t, v, StringDesc(b))
+
+		if prev != nil {
+			assert.Equal(t, -1, bytes.Compare(prev, b), "%q", v)
+		}
+		prev = b
+	}
+}
+
+func TestScanStringDesc(t *testing.T) {
+	v1, v2 := "jumped over the lazy dog", 42
+	slen := len(v1) + 1
+	b := make([]byte, slen+8)
+
+	PutStringDesc(b, v1)
+	PutIntDesc(b[slen:], v2)
+
+	assert.Equal(t, v1, ScanStringDesc(b))
+	assert.Equal(t, "", ScanStringDesc([]byte("howdy")))
+}
+
+func TestEscapedStringDesc_RandomCompare(t *testing.T) {
+	f := func(a1, a2 string) bool {
+		a1 = strings.Replace(a1, "a", "\x00", -1)
+		a2 = strings.Replace(a2, "a", "\x00", -1)
+
+		b1 := make([]byte, EscapedStringSize(a1))
+		PutEscapedStringDesc(b1, a1)
+
+		b2 := make([]byte, EscapedStringSize(a2))
+		PutEscapedStringDesc(b2, a2)
+
+		v1, n := ScanEscapedStringDesc(b1)
+		return bytes.Compare(b1, b2) == strings.Compare(a2, a1) && v1 == a1 && n == len(b1)
+	}
+	assert.Nil(t, quick.Check(f, nil))
+}
+
+func TestEscapedBytesDesc(t *testing.T) {
+	r := [][]byte{{0xFF}, {0x01}, {0x00, 0x01}, {0x00, 0x00}, {0x00}, {}}
+	var prev []byte
+	for _, v := range r {
+		b := make([]byte, EscapedBytesSize(v)+1)
+		PutEscapedBytesDesc(b, v)
+
+		v1, n := ScanEscapedBytesDesc(b)
+		assert.Equal(t, v, v1)
+		assert.Equal(t, len(b)-1, n)
+
+		if prev != nil {
+			assert.Equal(t, -1, bytes.Compare(prev, b), "%v", v)
+		}
+		prev = b
+	}
+
+	v, n := ScanEscapedBytesDesc([]byte{0xFF, 0xFD})
+	assert.Nil(t, v)
+	assert.Equal(t, -1, n)
+}
@@ -0,0 +1,134 @@
+package lex
+
+import (
+	"bytes"
+	"strings"
+)
+
+const (
+	escape     = 0x00
+	escapedNul = 0xFF
+	escapedEnd = 0x01
+)
+
+//EscapedStringSize returns the number of bytes PutEscapedString would generate to encode v.
+func EscapedStringSize(v string) int {
+	return len(v) + strings.Count(v, "\x00") + 2
+}
+
+//PutEscapedString serializes string as EscapedStringSize(v) bytes.
+//Each NUL character is escaped as 0x00 0xFF, and the value is terminated with 0x00 0x01.
+//Unlike PutString, order is preserved for strings containing NUL characters.
+func PutEscapedString(b []byte, v string) {
+	j := 0
+	for i := 0; i < len(v); i++ {
+		b[j] = v[i]
+		j++
+		if v[i] == escape {
+			b[j] = escapedNul
+			j++
+		}
+	}
+	b[j] = escape
+	b[j+1] = escapedEnd
+}
+
+//ScanEscapedString deserializes string from byte slice, returning the value and the number of bytes read.
+//Assumes that other values may be stored after the encoded string value.
+//If b does not begin with a valid escaped string, ScanEscapedString returns "" and -1.
+func ScanEscapedString(b []byte) (string, int) {
+	v, n := unescape(b, 0)
+	if n < 0 {
+		return "", -1
+	}
+	return string(v), n
+}
+
+//EscapedBytesSize returns the number of bytes PutEscapedBytes would generate to encode v.
+func EscapedBytesSize(v []byte) int {
+	return len(v) + bytes.Count(v, []byte{escape}) + 2
+}
+
+//PutEscapedBytes serializes []byte as EscapedBytesSize(v) bytes.
+//Behaviour is identical to PutEscapedString.
+func PutEscapedBytes(b []byte, v []byte) {
+	j := 0
+	for i := 0; i < len(v); i++ {
+		b[j] = v[i]
+		j++
+		if v[i] == escape {
+			b[j] = escapedNul
+			j++
+		}
+	}
+	b[j] = escape
+	b[j+1] = escapedEnd
+}
+
+//ScanEscapedBytes deserializes []byte from byte slice, returning the value and the number of bytes read.
+//The value is always a copy, and never shares memory with b.
+//If b does not begin with a valid escaped value, ScanEscapedBytes returns nil and -1.
+func ScanEscapedBytes(b []byte) ([]byte, int) {
+	v, n := unescape(b, 0)
+	if n < 0 {
+		return nil, -1
+	}
+	if n == len(v)+2 {
+		//no escaped NULs, so v is a view of b
+		v = append(make([]byte, 0, len(v)), v...)
+	}
+	return v, n
+}
+
+//unescape decodes an escaped value from the start of b, returning the value and the number of bytes read, or nil and -1 if invalid.
+//Each byte of b is first combined with mask, so that a mask of 0xFF decodes a descending value.
+//When mask is zero and the value contains no escaped NUL characters, the result shares its underlying array with b.
+func unescape(b []byte, mask byte) ([]byte, int) {
+	v, n, err := readEscaped(b, mask)
+	if err != nil {
+		return nil, -1
+	}
+	return v, n
+}
+
+//readEscaped is as per unescape, returning ErrMissingTerminator if b ends before the terminator, or ErrInvalid if an escape is invalid.
+func readEscaped(b []byte, mask byte) ([]byte, int, error) {
+	i := bytes.IndexByte(b, escape^mask)
+	if i < 0 || i+1 >= len(b) {
+		return nil, 0, ErrMissingTerminator
+	}
+	if mask == 0 && b[i+1] == escapedEnd {
+		return b[:i:i], i + 2, nil
+	}
+
+	v := make([]byte, 0, len(b))
+	v = appendMasked(v, b[:i], mask)
+	for {
+		switch b[i+1] ^ mask {
+		case escapedEnd:
+			return v, i + 2, nil
+		case escapedNul:
+			v = append(v, escape)
+			i += 2
+		default:
+			return nil, 0, ErrInvalid
+		}
+
+		j := bytes.IndexByte(b[i:], escape^mask)
+		if j < 0 || i+j+1 >= len(b) {
+			return nil, 0, ErrMissingTerminator
+		}
+		v = appendMasked(v, b[i:i+j], mask)
+		i += j
+	}
+}
+
+func appendMasked(dst, b []byte, mask byte) []byte {
+	if mask == 0 {
+		return append(dst, b...)
+	}
+	for _, c := range b {
+		dst = append(dst, c^mask)
+	}
+	return dst
+}
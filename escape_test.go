@@ -0,0 +1,156 @@
+package lex
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"testing/quick"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEscapedString(t *testing.T) {
+	r := []string{"", "\x00", "\x00\x00", "\x00\x01", "\x00\xff", "\x01", "a", "a\x00", "a\x00b", "a\x01", "ab", "b", "\xff"}
+	var prev []byte
+	for _, v := range r {
+		b := make([]byte, EscapedStringSize(v))
+		PutEscapedString(b, v)
+
+		v1, n := ScanEscapedString(b)
+		assert.Equal(t, v, v1)
+		assert.Equal(t, len(b), n)
+
+		if prev != nil {
+			assert.Equal(t, -1, bytes.Compare(prev, b), "%q", v)
+		}
+		prev = b
+	}
+}
+
+func TestEscapedString_format(t *testing.T) {
+	b := make([]byte, EscapedStringSize("a\x00b"))
+	PutEscapedString(b, "a\x00b")
+	assert.Equal(t, []byte{'a', 0x00, 0xFF, 'b', 0x00, 0x01}, b)
+}
+
+func TestEscapedString_Random(t *testing.T) {
+	f := func(a1 string) bool {
+		b1 := make([]byte, EscapedStringSize(a1))
+		PutEscapedString(b1, a1)
+		v1, n := ScanEscapedString(b1)
+		return v1 == a1 && n == len(b1)
+	}
+	assert.Nil(t, quick.Check(f, nil))
+}
+
+func TestEscapedString_RandomCompare(t *testing.T) {
+	f := func(a1, a2 string, x1, x2 uint8) bool {
+		//embed some NULs, and follow each value with another, to check that order holds within a composite key
+		a1 = strings.Replace(a1, "a", "\x00", -1)
+		a2 = strings.Replace(a2, "a", "\x00", -1)
+
+		n1 := EscapedStringSize(a1)
+		b1 := make([]byte, n1+1)
+		PutEscapedString(b1, a1)
+		PutUint8(b1[n1:], x1)
+
+		n2 := EscapedStringSize(a2)
+		b2 := make([]byte, n2+1)
+		PutEscapedString(b2, a2)
+		PutUint8(b2[n2:], x2)
+
+		expected := strings.Compare(a1, a2)
+		if expected == 0 {
+			expected = bytes.Compare([]byte{x1}, []byte{x2})
+		}
+		return bytes.Compare(b1, b2) == expected
+	}
+	assert.Nil(t, quick.Check(f, nil))
+}
+
+func TestEscapedString_ZeroAllocs(t *testing.T) {
+	v := "jumped over the lazy dog"
+	b := make([]byte, EscapedStringSize(v))
+	assert.Zero(t, testing.AllocsPerRun(1, func() { PutEscapedString(b, v) }))
+}
+
+func TestEscapedBytes(t *testing.T) {
+	r := [][]byte{{}, {0x00}, {0x00, 0x00}, {0x00, 0x01}, {0x00, 0xFF}, {0x01}, {0x01, 0x00}, {0xFF}, {0xFF, 0x00}, {0xFF, 0xFF}}
+	var prev []byte
+	for _, v := range r {
+		b := make([]byte, EscapedBytesSize(v))
+		PutEscapedBytes(b, v)
+
+		v1, n := ScanEscapedBytes(b)
+		assert.Equal(t, v, v1)
+		assert.Equal(t, len(b), n)
+
+		if prev != nil {
+			assert.Equal(t, -1, bytes.Compare(prev, b), "%v", v)
+		}
+		prev = b
+	}
+}
+
+func TestEscapedBytes_RandomCompare(t *testing.T) {
+	f := func(a1, a2 []byte) bool {
+		b1 := make([]byte, EscapedBytesSize(a1)+1)
+		PutEscapedBytes(b1, a1)
+		b1[len(b1)-1] = 0xFF //trailing data must not affect order
+
+		b2 := make([]byte, EscapedBytesSize(a2))
+		PutEscapedBytes(b2, a2)
+
+		expected := bytes.Compare(a1, a2)
+		if expected == 0 {
+			expected = 1
+		}
+		return bytes.Compare(b1, b2) == expected
+	}
+	assert.Nil(t, quick.Check(f, nil))
+}
+
+func TestEscapedBytes_ZeroAllocs(t *testing.T) {
+	v := []byte("jumped\x00over the lazy dog")
+	b := make([]byte, EscapedBytesSize(v))
+	assert.Zero(t, testing.AllocsPerRun(1, func() { PutEscapedBytes(b, v) }))
+}
+
+func TestScanEscapedBytes_copy(t *testing.T) {
+	v := []byte("howdy")
+	b := make([]byte, EscapedBytesSize(v))
+	PutEscapedBytes(b, v)
+
+	v1, _ := ScanEscapedBytes(b)
+	b[0] = 'c'
+	assert.Equal(t, v, v1) //modifying b does not affect v1
+}
+
+func TestScanEscapedString(t *testing.T) {
+	v1, v2 := "jumped\x00over", 42
+	slen := EscapedStringSize(v1)
+	b := make([]byte, slen+8)
+
+	PutEscapedString(b, v1)
+	PutInt(b[slen:], v2)
+
+	s, n := ScanEscapedString(b)
+	assert.Equal(t, v1, s)
+	assert.Equal(t, slen, n)
+	assert.Equal(t, v2, Int(b[n:]))
+}
+
+func TestScanEscapedString_badformat(t *testing.T) {
+	var tests = [][]byte{
+		nil,                     //no bytes at all
+		[]byte("howdy"),         //string but no terminator
+		[]byte("howdy\x00"),     //truncated terminator
+		[]byte("how\x00\xffdy"), //escaped NUL but no terminator
+		[]byte("how\x00\x02dy"), //invalid escape
+	}
+	for _, b := range tests {
+		s, n := ScanEscapedString(b)
+		assert.Equal(t, "", s)
+		assert.Equal(t, -1, n)
+	}
+}
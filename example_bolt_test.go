@@ -58,21 +58,22 @@ func Example() {
 			b.Put(k, v)
 		}
 
-		//range seek on first part of key
+		//range seek on first part of key, over the half-open range [start, end)
 		//year >= 1950 && year < 1970
-		min, _ := lex.Key(int16(1950))
-		max, _ := lex.Key(int16(1970))
+		//every key for 1970 has Key(int16(1970)) as a prefix, so sorts after it and is excluded
+		start, _ := lex.Key(int16(1950))
+		end, _ := lex.Key(int16(1970))
 		c := b.Cursor()
-		for k, v := c.Seek(min); bytes.Compare(k, max) <= 0; k, v = c.Next() {
+		for k, v := c.Seek(start); k != nil && bytes.Compare(k, end) < 0; k, v = c.Next() {
 			fmt.Printf("%v (%v)\n", string(v), lex.Int16(k))
 		}
 
 		//exact match on first + range seek on second part of key
 		//year == 1994 && rating >= 9.0
-		start, _ := lex.Key(int16(1994), float32(9.0))
-		prefix, _ := lex.Key(int16(1994))
+		start, _ = lex.Key(int16(1994), float32(9.0))
+		end = lex.PrefixEnd(lex.MustKey(int16(1994)))
 		c = b.Cursor()
-		for k, v := c.Seek(start); bytes.HasPrefix(k, prefix); k, v = c.Next() {
+		for k, v := c.Seek(start); k != nil && bytes.Compare(k, end) < 0; k, v = c.Next() {
 			fmt.Println(string(v))
 		}
 
@@ -96,8 +97,9 @@ func Example() {
 
 		//title HasPrefix "The Godfather"
 		prefix := []byte("The Godfather") //note that this isn't NUL terminated
+		end := lex.PrefixEnd(prefix)
 		c := b.Cursor()
-		for k, v := c.Seek(prefix); bytes.HasPrefix(k, prefix); k, v = c.Next() {
+		for k, v := c.Seek(prefix); k != nil && bytes.Compare(k, end) < 0; k, v = c.Next() {
 			fmt.Printf("%v %v\n", lex.String(k), string(v))
 		}
 
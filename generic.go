@@ -0,0 +1,156 @@
+//go:build go1.18
+
+package lex
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+//SizeOf returns the number of bytes Encode would append to encode v, or -1 if v cannot be encoded.
+//Boolean, Numeric, String, []byte, time.Time and Decimal values are sized directly, selected by a type switch at run time,
+//and other types as per Size, with v boxed in an interface{}.
+func SizeOf[T any](v T) int {
+	switch x := interface{}(v).(type) {
+	case bool, int8, uint8:
+		return 1
+	case int16, uint16:
+		return 2
+	case int32, uint32, float32:
+		return 4
+	case int, uint, int64, uint64, float64, complex64:
+		return 8
+	case complex128:
+		return 16
+	case string:
+		return len(x) + 1
+	case []byte:
+		return EscapedBytesSize(x)
+	case time.Time:
+		if !timeInRange(x, time.Nanosecond) {
+			return -1
+		}
+		return TimeSize(time.Nanosecond)
+	case Decimal:
+		return DecimalSize(x)
+	}
+	return Size(v)
+}
+
+//Encode appends the encoding of v to dst, as per PutReflect, and returns the extended slice.
+//Boolean, Numeric, String, []byte, time.Time and Decimal values are encoded directly, without reflection or allocation.
+//The encoding is selected by a type switch at run time, not by the compiler, so values of other types, including structs
+//and defined types such as `type ID uint16`, are boxed in an interface{} and encoded via the reflection plan cached for their type.
+//Values wrapped with options such as Desc are also supported.
+//
+//As T is unconstrained, Encode accepts types that cannot be encoded, such as maps, and panics with the error from AppendKey.
+func Encode[T any](dst []byte, v T) []byte {
+	switch x := interface{}(v).(type) {
+	case bool:
+		return AppendBool(dst, x)
+	case int:
+		return AppendInt(dst, x)
+	case int8:
+		return AppendInt8(dst, x)
+	case int16:
+		return AppendInt16(dst, x)
+	case int32:
+		return AppendInt32(dst, x)
+	case int64:
+		return AppendInt64(dst, x)
+	case uint:
+		return AppendUint(dst, x)
+	case uint8:
+		return AppendUint8(dst, x)
+	case uint16:
+		return AppendUint16(dst, x)
+	case uint32:
+		return AppendUint32(dst, x)
+	case uint64:
+		return AppendUint64(dst, x)
+	case float32:
+		return AppendFloat32(dst, x)
+	case float64:
+		return AppendFloat64(dst, x)
+	case complex64:
+		return AppendComplex64(dst, x)
+	case complex128:
+		return AppendComplex128(dst, x)
+	case string:
+		return AppendString(dst, x)
+	case []byte:
+		return AppendEscapedBytes(dst, x)
+	case time.Time:
+		return AppendTime(dst, x, time.Nanosecond)
+	case Decimal:
+		return AppendDecimal(dst, x)
+	}
+
+	b, err := AppendKey(dst, v)
+	if err != nil {
+		panic(fmt.Errorf("lex.Encode: %w", err))
+	}
+	return b
+}
+
+//Decode reads a value of type T from the start of b, as per Reflect, returning the value and the number of bytes read.
+//Boolean, Numeric, String, []byte, time.Time and Decimal values are decoded directly, without reflection or allocation
+//other than for the value itself, as selected by a type switch at run time. Other types are decoded via the reflection plan cached for their type,
+//and if they cannot be decoded, such as maps, Decode returns an error at run time.
+//Unlike Reflect, a string must be terminated, as other values may follow it.
+//If b does not begin with a valid value of type T, Decode returns an error such as ErrShortBuffer or ErrMissingTerminator.
+func Decode[T any](b []byte) (v T, n int, err error) {
+	//the type switch is on &v itself, rather than an interface{} passed to a helper, so that v does not escape to the heap
+	switch p := interface{}(&v).(type) {
+	case *bool:
+		*p, n, err = ReadBool(b)
+	case *int:
+		*p, n, err = ReadInt(b)
+	case *int8:
+		*p, n, err = ReadInt8(b)
+	case *int16:
+		*p, n, err = ReadInt16(b)
+	case *int32:
+		*p, n, err = ReadInt32(b)
+	case *int64:
+		*p, n, err = ReadInt64(b)
+	case *uint:
+		*p, n, err = ReadUint(b)
+	case *uint8:
+		*p, n, err = ReadUint8(b)
+	case *uint16:
+		*p, n, err = ReadUint16(b)
+	case *uint32:
+		*p, n, err = ReadUint32(b)
+	case *uint64:
+		*p, n, err = ReadUint64(b)
+	case *float32:
+		*p, n, err = ReadFloat32(b)
+	case *float64:
+		*p, n, err = ReadFloat64(b)
+	case *complex64:
+		*p, n, err = ReadComplex64(b)
+	case *complex128:
+		*p, n, err = ReadComplex128(b)
+	case *string:
+		*p, n, err = ReadString(b)
+	case *[]byte:
+		*p, n, err = ReadEscapedBytes(b)
+	case *time.Time:
+		*p, n, err = ReadTime(b, time.Nanosecond)
+	case *Decimal:
+		*p, n, err = ReadDecimal(b)
+	default:
+		x := reflect.New(reflect.TypeOf((*T)(nil)).Elem()).Elem()
+		n, err = _reflect(b, x, 0)
+		if err == nil {
+			v = x.Interface().(T)
+		}
+	}
+	if err != nil {
+		var zero T
+		return zero, 0, err
+	}
+	return v, n, nil
+}
@@ -0,0 +1,159 @@
+//go:build go1.18
+
+package lex_test
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/xcdb/lex"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//roundTrip checks that v is encoded as per Key, and decoded back to itself.
+func roundTrip[T any](t *testing.T, v T) {
+	k := lex.MustKey(v)
+	assert.Equal(t, len(k), lex.SizeOf(v), "%T", v)
+
+	prefix := []byte{0xFF}
+	b := lex.Encode(prefix[:1:1], v)
+	assert.Equal(t, append([]byte{0xFF}, k...), b, "%T", v)
+
+	//decoding must not read beyond the value
+	d, n, err := lex.Decode[T](append(k, 0xAB))
+	assert.Nil(t, err, "%T", v)
+	assert.Equal(t, len(k), n, "%T", v)
+	assert.Equal(t, v, d, "%T", v)
+}
+
+func TestEncodeDecode(t *testing.T) {
+	roundTrip(t, true)
+	roundTrip(t, -42)
+	roundTrip(t, int8(-42))
+	roundTrip(t, int16(-42))
+	roundTrip(t, int32(-42))
+	roundTrip(t, int64(-42))
+	roundTrip(t, uint(42))
+	roundTrip(t, uint8(42))
+	roundTrip(t, uint16(42))
+	roundTrip(t, uint32(42))
+	roundTrip(t, uint64(42))
+	roundTrip(t, float32(-4.2))
+	roundTrip(t, 4.2)
+	roundTrip(t, complex64(1+2i))
+	roundTrip(t, 1+2i)
+	roundTrip(t, "hello")
+	roundTrip(t, []byte("a\x00b"))
+	roundTrip(t, time.Unix(1, 2).UTC())
+	roundTrip(t, aliasedInt(-42))
+	roundTrip(t, testStruct{-1, "a", 2.5})
+	roundTrip(t, []uint16{1, 2})
+	roundTrip(t, taggedStruct{Name: "a\x00b", ID: 7, Score: -3})
+}
+
+func TestDecode_decimal(t *testing.T) {
+	v, _ := lex.ParseDecimal("-123.4500")
+	k := lex.MustKey(v)
+	assert.Equal(t, k, lex.Encode(nil, v))
+	assert.Equal(t, len(k), lex.SizeOf(v))
+
+	d, n, err := lex.Decode[lex.Decimal](k)
+	assert.Nil(t, err)
+	assert.Equal(t, len(k), n)
+	assert.Equal(t, "-123.45", d.String())
+}
+
+func TestEncode_options(t *testing.T) {
+	assert.Equal(t, lex.MustKey(lex.Desc(42)), lex.Encode(nil, lex.Desc(42)))
+	assert.Equal(t, lex.Size(lex.Varint(42)), lex.SizeOf(lex.Varint(42)))
+
+	var v interface{} = "hello"
+	assert.Equal(t, lex.MustKey(v), lex.Encode(nil, v))
+}
+
+func TestEncode_invalid(t *testing.T) {
+	assert.Equal(t, -1, lex.SizeOf(map[string]int{}))
+	assert.Panics(t, func() { lex.Encode(nil, map[string]int{}) })
+	assert.Panics(t, func() { lex.Encode(nil, invalidStruct{}) })
+
+	//the panic wraps the error from AppendKey
+	_, want := lex.AppendKey(nil, invalidStruct{})
+	assert.PanicsWithError(t, "lex.Encode: "+want.Error(), func() { lex.Encode(nil, invalidStruct{}) })
+}
+
+func TestEncode_allocs(t *testing.T) {
+	b := make([]byte, 0, 64)
+	tm := time.Unix(1, 2)
+	allocs := testing.AllocsPerRun(100, func() {
+		lex.Encode(b, int64(-42))
+		lex.Encode(b, "hello")
+		lex.Encode(b, tm)
+		lex.SizeOf(int64(-42))
+		lex.SizeOf("hello")
+		lex.SizeOf(tm)
+	})
+	assert.Equal(t, 0.0, allocs)
+
+	//other types are boxed
+	allocs = testing.AllocsPerRun(100, func() {
+		lex.Encode(b, struct{ A, B int64 }{1, 2})
+	})
+	assert.Equal(t, 1.0, allocs)
+}
+
+func TestDecode_invalid(t *testing.T) {
+	_, _, err := lex.Decode[int64]([]byte{1, 2, 3})
+	assert.NotNil(t, err)
+	_, _, err = lex.Decode[string]([]byte("unterminated"))
+	assert.NotNil(t, err)
+	_, _, err = lex.Decode[[]byte]([]byte{1, 2, 3})
+	assert.NotNil(t, err)
+	_, _, err = lex.Decode[map[string]int]([]byte{0})
+	assert.NotNil(t, err)
+	_, _, err = lex.Decode[interface{}]([]byte{0})
+	assert.NotNil(t, err)
+
+	x, n, err := lex.Decode[*big.Int](lex.MustKey(big.NewInt(-7)))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(-7), x.Int64())
+	assert.Equal(t, lex.Size(big.NewInt(-7)), n)
+}
+
+func TestDecode_allocs(t *testing.T) {
+	i, f, c, tm := lex.MustKey(int64(-42)), lex.MustKey(4.2), lex.MustKey(1+2i), lex.MustKey(time.Unix(1, 2))
+	allocs := testing.AllocsPerRun(100, func() {
+		lex.Decode[int64](i)
+		lex.Decode[float64](f)
+		lex.Decode[complex128](c)
+		lex.Decode[time.Time](tm)
+	})
+	assert.Equal(t, 0.0, allocs)
+}
+
+//
+
+func BenchmarkEncodeInt(b *testing.B) {
+	v := 64
+	bs := make([]byte, 0, 8)
+	for n := 0; n < b.N; n++ {
+		lex.Encode(bs, v)
+	}
+}
+
+func BenchmarkEncodeStruct(b *testing.B) {
+	v := testStruct{}
+	bs := make([]byte, 0, 32)
+	for n := 0; n < b.N; n++ {
+		lex.Encode(bs, v)
+	}
+}
+
+func BenchmarkDecodeInt(b *testing.B) {
+	k := lex.MustKey(64)
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		lex.Decode[int](k)
+	}
+}
@@ -7,131 +7,211 @@
 //Lex provides functions that allow the safe usage of many more types with the default bytewise comparison. Efficient implementations are provided for many core types, with structs and aliased types also supported via a reflection-based approach.
 //
 //Boolean and Numeric types are encoded as appropriate fixed-size values, while Strings are encoded simply as their underlying bytes with a single `NUL` character appended. Note that type information is *not* serialized with the value, and needs to be maintained separately.
+//
+//Strings which may contain `NUL` characters can instead be escaped, either directly with PutEscapedString or by wrapping values passed to Key with Escaped. Byte slices are always escaped.
+//
+//Each Put function has an Append counterpart, such as AppendInt64, which grows the slice as required, in the manner of strconv.AppendInt. Similarly, AppendKey builds keys into a reused buffer without first calling Size.
+//
+//Functions such as Int64 assume a well-formed input and panic if b is too short. Their Read counterparts, such as ReadInt64, instead return ErrShortBuffer, ErrMissingTerminator or ErrInvalid, as does Reflect, so that corrupt keys read from storage can be rejected safely.
+//
+//To decode a composite key without offset arithmetic, NewDecoder returns a Decoder that reads each value in turn, with a sticky error, mirroring Key. Unkey reads a whole key into a list of pointers, as the inverse of Key.
+//
+//Range scans are simplest over half-open ranges. PrefixEnd returns the exclusive end of the range of keys sharing a prefix, such as all keys built by Key with the same leading values, while KeySuccessor and KeyPredecessor step to adjacent keys.
+//
+//Values sort in ascending order by default. Wrap a value passed to Key with Desc, or tag a struct field with `lex:"desc"`, to sort it in descending order instead. Similarly, nil pointers are supported when marked with NullsFirst or NullsLast.
+//
+//Integers which are usually small can be encoded in fewer bytes by wrapping them with Varint; see PutVarint and PutUvarint.
+//
+//Where values of different numeric types must be compared, such as int32 and float64, wrap them with Number to use a single encoding shared by all integers and floats; see PutNumberInt64.
+//
+//Alternatively, TaggedKey precedes each value with a type code, so that keys can be read back by ParseTaggedKey without separately maintained type information.
+//
+//For compatibility with TiDB and MyRocks, wrap values with Memcomparable to use the memcomparable format; see PutMemcomparableBytes.
+//
+//Custom types may define their own order-preserving encoding by implementing Marshaler and Unmarshaler, which take precedence over the reflection-based approach.
+//
+//Exported struct fields are encoded in declaration order, and unexported fields are ignored. The `lex` struct tag can skip a field with `lex:"-"`, move it with `lex:"pos=N"`, or select an encoding option such as `lex:"varint"`.
+//
+//With Go 1.18 or later, Encode, Decode and SizeOf provide a generic alternative to Key and Reflect, selecting an encoding by a type switch at run time. Primitives are encoded without reflection or allocation, while other types, such as structs, are boxed and encoded via reflection, and unsupported types are only rejected at run time.
 package lex
 
 import (
 	"errors"
+	"fmt"
+	"math"
+	"math/big"
 	"reflect"
+	"time"
 )
 
-//Size returns the number of bytes PutReflect would generate to encode the value d.
-//Data must be of Boolean, Numeric or String based type, or a pointer to such data.
-//If d is not of a supported type, Size returns -1.
-func Size(d interface{}) int {
-	return size(reflect.ValueOf(d))
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	bigIntType  = reflect.TypeOf(big.Int{})
+	decimalType = reflect.TypeOf(Decimal{})
+)
+
+//bigIntOf returns the *big.Int held by v, or nil if v cannot be accessed.
+func bigIntOf(v reflect.Value) *big.Int {
+	if v.CanAddr() && v.CanInterface() {
+		return v.Addr().Interface().(*big.Int)
+	}
+	if v.CanInterface() {
+		x := v.Interface().(big.Int)
+		return &x
+	}
+	return nil
 }
 
-func size(v reflect.Value) int {
-	v = reflect.Indirect(v)
+//Slices other than []byte are encoded as a sequence of elements, each preceded by sliceElem, followed by sliceEnd.
+//As sliceEnd sorts before sliceElem, a slice sorts before any longer slice that it is a prefix of.
+const (
+	sliceEnd  = 0x00
+	sliceElem = 0x01
+)
+
+//Nullable values are preceded by a marker, so that nil sorts either before or after all other values.
+const (
+	nullFirst = 0x00
+	notNull   = 0x01
+	nullLast  = 0x02
+)
+
+//setNumber stores x, as returned by ScanNumber, in v, reporting false if v cannot represent it exactly.
+func setNumber(v reflect.Value, x interface{}) bool {
 	switch v.Kind() {
-	case reflect.String:
-		return v.Len() + 1
-
-	case reflect.Bool:
-		return 1
-
-	case reflect.Uint, reflect.Int:
-		return 8
-
-	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
-		reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
-		reflect.Float32, reflect.Float64,
-		reflect.Complex64, reflect.Complex128:
-		return int(v.Type().Size())
-
-	case reflect.Struct:
-		sum := 0
-		for i, n := 0, v.NumField(); i < n; i++ {
-			s := size(v.Field(i))
-			if s < 0 {
-				return -1
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, ok := x.(int64)
+		if !ok || v.OverflowInt(i) {
+			return false
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var u uint64
+		switch x := x.(type) {
+		case int64:
+			if x < 0 {
+				return false
 			}
-			sum += s
+			u = uint64(x)
+		case uint64:
+			u = x
+		default:
+			return false
 		}
-		if sum == 0 {
-			return -1
+		if v.OverflowUint(u) {
+			return false
 		}
-		return sum
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		switch x := x.(type) {
+		case int64:
+			f = float64(x)
+			if f >= math.MaxInt64 || int64(f) != x {
+				return false
+			}
+		case uint64:
+			f = float64(x)
+			if f >= math.MaxUint64 || uint64(f) != x {
+				return false
+			}
+		case float64:
+			f = x
+		}
+		if v.Kind() == reflect.Float32 && float64(float32(f)) != f && !math.IsNaN(f) {
+			return false
+		}
+		v.SetFloat(f)
+	default:
+		return false
 	}
+	return true
+}
+
+func isNil(v reflect.Value) bool {
+	return !v.IsValid() || (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil()
+}
+
+func nullMarker(o opts) byte {
+	if o&optNullsFirst != 0 {
+		return nullFirst
+	}
+	return nullLast
+}
 
-	return -1
+//Size returns the number of bytes PutReflect would generate to encode the value d.
+//Data must be of Boolean, Numeric, String or slice based type, or a pointer to such data.
+//If d is not of a supported type, or is a struct with an invalid lex tag, Size returns -1.
+func Size(d interface{}) int {
+	d, o := unwrap(d)
+	return size(reflect.ValueOf(d), o)
+}
+
+func size(v reflect.Value, o opts) int {
+	if !v.IsValid() {
+		if o&optNullable != 0 {
+			return 1
+		}
+		return -1
+	}
+	return codecFor(v.Type(), o).size(v)
 }
 
 //PutReflect writes a lexicographically encoded representation of data into b.
-//Data must be of Boolean, Numeric or String type, or a pointer to such data.
+//Data must be of Boolean, Numeric, String or slice type, or a pointer to such data.
+//A []byte is always escaped, as per PutEscapedBytes.
+//Structs and arrays are encoded as the concatenation of their fields or elements, with no terminator.
+//Exported struct fields are encoded in declaration order, unless tagged:
+//`lex:"-"` skips a field, `lex:"pos=N"` encodes it at position N among the encoded fields, counting from zero,
+//and desc, nullsfirst, nullslast, escaped, varint, number and memcomparable select the corresponding option for the field, as in `lex:"varint,desc"`.
+//Unexported fields are ignored, and may only be tagged `lex:"-"`.
+//A tag with an unknown option, or a position that is repeated or out of range, is reported as an error naming the field.
+//A time.Time is encoded at nanosecond precision, as per PutTime, a big.Int as per PutBigInt, and a Decimal as per PutDecimal.
+//Other slices are encoded element by element, such that a slice sorts before any longer slice that it is a prefix of.
+//Values implementing Marshaler are encoded by their MarshalLex method instead.
 func PutReflect(b []byte, data interface{}) error {
-	i := putReflect(b, reflect.ValueOf(data))
+	data, o := unwrap(data)
+	v := reflect.ValueOf(data)
+	i := putReflect(b, v, o)
 	if i < 0 {
-		return errors.New("lex.PutReflect: invalid")
+		return invalidErr("lex.PutReflect", v, o)
 	}
 	return nil
 }
 
-func putReflect(b []byte, v reflect.Value) int {
-	v = reflect.Indirect(v)
-	switch v.Kind() {
-	case reflect.String:
-		s := v.String()
-		PutString(b, s)
-		return len(s) + 1
-	case reflect.Bool:
-		PutBool(b, v.Bool())
-		return 1
-	case reflect.Int:
-		PutInt(b, int(v.Int()))
-		return 8
-	case reflect.Uint:
-		PutUint(b, uint(v.Uint()))
-		return 8
-	case reflect.Int8:
-		PutInt8(b, int8(v.Int()))
-	case reflect.Uint8:
-		PutUint8(b, uint8(v.Uint()))
-	case reflect.Int16:
-		PutInt16(b, int16(v.Int()))
-	case reflect.Uint16:
-		PutUint16(b, uint16(v.Uint()))
-	case reflect.Int32:
-		PutInt32(b, int32(v.Int()))
-	case reflect.Uint32:
-		PutUint32(b, uint32(v.Uint()))
-	case reflect.Int64:
-		PutInt64(b, v.Int())
-	case reflect.Uint64:
-		PutUint64(b, v.Uint())
-	case reflect.Float32:
-		PutFloat32(b, float32(v.Float()))
-	case reflect.Float64:
-		PutFloat64(b, v.Float())
-	case reflect.Complex64:
-		PutComplex64(b, complex64(v.Complex()))
-	case reflect.Complex128:
-		PutComplex128(b, v.Complex())
-	case reflect.Struct:
-		sum := 0
-		for i, n := 0, v.NumField(); i < n; i++ {
-			s := putReflect(b[sum:], v.Field(i))
-			if s < 0 {
-				return -1
-			}
-			sum += s
+//invalidErr returns the error reported by fn for value v that cannot be encoded with options o,
+//including the reason if known, such as an invalid struct tag.
+func invalidErr(fn string, v reflect.Value, o opts) error {
+	if v.IsValid() {
+		if err := codecFor(v.Type(), o).err; err != nil {
+			return fmt.Errorf("%s: %w", fn, err)
 		}
-		if sum == 0 {
-			return -1
+	}
+	return errors.New(fn + ": invalid")
+}
+
+func putReflect(b []byte, v reflect.Value, o opts) int {
+	if !v.IsValid() {
+		if o&optNullable != 0 {
+			b[0] = nullMarker(o)
+			return 1
 		}
-		return sum
-	default:
 		return -1
 	}
-	return int(v.Type().Size())
+	return codecFor(v.Type(), o).put(b, v)
 }
 
 //Reflect reads lexicographically encoded data from b into data.
-//Data must be a pointer to a Boolean, Numeric or String based type.
-//When reading into a struct, all fields must be exported.
+//Data must be a pointer to a Boolean, Numeric, String or slice based type.
+//Exported struct fields are read in the order selected by their lex tags, and unexported fields are left unchanged; see PutReflect.
+//Values whose address implements Unmarshaler are decoded by their UnmarshalLex method instead.
+//
+//If b does not hold a valid encoding, Reflect returns ErrShortBuffer, ErrMissingTerminator or ErrInvalid rather than panicking,
+//so keys read from storage may be decoded safely.
 func Reflect(b []byte, data interface{}) error {
+	data, o := unwrap(data)
 	v := reflect.ValueOf(data)
-	if v.Kind() != reflect.Ptr {
+	if v.Kind() != reflect.Ptr || v.IsNil() {
 		return errors.New("lex.Reflect: invalid (data must be a pointer)")
 	}
 
@@ -139,76 +219,20 @@ func Reflect(b []byte, data interface{}) error {
 
 	//if data is string, then we can assume the whole slice is the string value
 	//and avoid the much more expensive ScanString operation
-	if v.Kind() == reflect.String {
+	if v.Kind() == reflect.String && o == 0 && unmarshaler(v) == nil {
+		if len(b) == 0 {
+			return ErrMissingTerminator
+		}
 		v.SetString(String(b))
 		return nil
 	}
 
-	i := _reflect(b, v)
-	if i < 0 {
-		return errors.New("lex.Reflect: invalid")
-	}
-	return nil
+	_, err := _reflect(b, v, o)
+	return err
 }
 
-func _reflect(b []byte, v reflect.Value) int {
-	switch v.Kind() {
-	case reflect.String:
-		s := ScanString(b)
-		v.SetString(s)
-		return len(s) + 1
-	case reflect.Bool:
-		v.SetBool(Bool(b))
-		return 1
-	case reflect.Int:
-		v.SetInt(int64(Int(b)))
-		return 8
-	case reflect.Uint:
-		v.SetUint(uint64(Uint(b)))
-		return 8
-	case reflect.Int8:
-		v.SetInt(int64(Int8(b)))
-	case reflect.Uint8:
-		v.SetUint(uint64(Uint8(b)))
-	case reflect.Int16:
-		v.SetInt(int64(Int16(b)))
-	case reflect.Uint16:
-		v.SetUint(uint64(Uint16(b)))
-	case reflect.Int32:
-		v.SetInt(int64(Int32(b)))
-	case reflect.Uint32:
-		v.SetUint(uint64(Uint32(b)))
-	case reflect.Int64:
-		v.SetInt(Int64(b))
-	case reflect.Uint64:
-		v.SetUint(Uint64(b))
-	case reflect.Float32:
-		v.SetFloat(float64(Float32(b)))
-	case reflect.Float64:
-		v.SetFloat(Float64(b))
-	case reflect.Complex64:
-		v.SetComplex(complex128(Complex64(b)))
-	case reflect.Complex128:
-		v.SetComplex(Complex128(b))
-	case reflect.Struct:
-		sum := 0
-		for i, n := 0, v.NumField(); i < n; i++ {
-			if f := v.Field(i); f.CanSet() {
-				s := _reflect(b[sum:], f)
-				if s < 0 {
-					return -1
-				}
-				sum += s
-			}
-		}
-		if sum == 0 {
-			return -1
-		}
-		return sum
-	default:
-		return -1
-	}
-	return int(v.Type().Size())
+func _reflect(b []byte, v reflect.Value, o opts) (int, error) {
+	return codecFor(v.Type(), o).get(b, v, 0)
 }
 
 //Key creates an appropriately-sized slice and writes passed data to it.
@@ -221,7 +245,8 @@ func Key(data ...interface{}) ([]byte, error) {
 	for _, d := range data {
 		n := Size(d)
 		if n < 0 {
-			return nil, errors.New("lex.Key: invalid")
+			d, o := unwrap(d)
+			return nil, invalidErr("lex.Key", reflect.ValueOf(d), o)
 		}
 		sum += n
 	}
@@ -230,14 +255,62 @@ func Key(data ...interface{}) ([]byte, error) {
 	offset := 0
 
 	for _, d := range data {
-		bs := b[offset:]
-		PutReflect(bs, d)
-		offset += Size(d)
+		d, o := unwrap(d)
+		v := reflect.ValueOf(d)
+		n := putReflect(b[offset:], v, o)
+		if n < 0 {
+			return nil, invalidErr("lex.Key", v, o)
+		}
+		offset += n
 	}
 
 	return b, nil
 }
 
+//AppendKey appends the encoding of passed data to dst, as per Key, returning the extended slice.
+//The slice is grown as required, so that keys can be built in a reused buffer without first calling Size.
+//If any data is invalid, AppendKey returns dst unchanged and an error.
+func AppendKey(dst []byte, data ...interface{}) ([]byte, error) {
+	n := len(dst)
+	for _, d := range data {
+		d, o := unwrap(d)
+		v := reflect.ValueOf(d)
+		s := size(v, o)
+		if s < 0 {
+			return dst[:n], invalidErr("lex.AppendKey", v, o)
+		}
+		var b []byte
+		dst, b = extend(dst, s)
+		if putReflect(b, v, o) < 0 {
+			return dst[:n], invalidErr("lex.AppendKey", v, o)
+		}
+	}
+	return dst, nil
+}
+
+//Unkey reads a key created by Key into the pointers in dst, one component each, as per Decoder.Reflect.
+//Pointers may be wrapped with options such as Desc, to match those passed to Key.
+//
+//If a component cannot be read, the error identifies it by index and wraps the cause, such as ErrShortBuffer.
+//If bytes remain after the last component, Unkey returns an error wrapping ErrTrailingBytes.
+func Unkey(b []byte, dst ...interface{}) error {
+	if len(dst) == 0 {
+		return errors.New("lex.Unkey: no data")
+	}
+
+	d := NewDecoder(b)
+	for i, p := range dst {
+		d.Reflect(p)
+		if err := d.Err(); err != nil {
+			return fmt.Errorf("lex.Unkey: component %d: %w", i, err)
+		}
+	}
+	if n := len(d.Remaining()); n > 0 {
+		return fmt.Errorf("lex.Unkey: %d bytes after component %d: %w", n, len(dst)-1, ErrTrailingBytes)
+	}
+	return nil
+}
+
 //MustKey panics if Key(data...) returns a non-nil error.
 func MustKey(data ...interface{}) []byte {
 	b, err := Key(data...)
@@ -3,8 +3,12 @@ package lex_test
 import (
 	"bytes"
 	"encoding/binary"
+	"errors"
 	"fmt"
+	"math/big"
+	"math/rand"
 	"reflect"
+	"sync"
 	"testing"
 	"time"
 
@@ -71,7 +75,6 @@ func TestPutReflect_struct_invalid(t *testing.T) {
 	var tests = []interface{}{
 		invalidStruct{},
 		struct{}{},
-		time.Time{},
 	}
 	b := make([]byte, 16)
 	for _, tt := range tests {
@@ -137,7 +140,6 @@ func TestReflect_struct_invalid(t *testing.T) {
 	var tests = []interface{}{
 		&invalidStruct{},
 		&struct{}{},
-		&time.Time{},
 	}
 	b := make([]byte, 16)
 	lex.PutInt(b, 42) //just something to decode
@@ -180,7 +182,6 @@ func TestSize_struct_invalid(t *testing.T) {
 	var tests = []interface{}{
 		invalidStruct{},
 		struct{}{},
-		time.Time{},
 	}
 	for _, tt := range tests {
 		i := lex.Size(tt)
@@ -276,6 +277,36 @@ func ExampleKey() {
 	// [128 42 193 19 51 51]
 }
 
+func TestAppendKey(t *testing.T) {
+	buf := make([]byte, 1, 64)
+	buf[0] = 0xAB
+
+	actual, err := lex.AppendKey(buf, int16(42), lex.Desc("abc"), testStruct{-1, "a", 2.5})
+	assert.Nil(t, err)
+	assert.Equal(t, append([]byte{0xAB}, lex.MustKey(int16(42), lex.Desc("abc"), testStruct{-1, "a", 2.5})...), actual)
+	assert.Equal(t, &buf[0], &actual[0])
+
+	actual, err = lex.AppendKey(buf[:0])
+	assert.Nil(t, err)
+	assert.Empty(t, actual)
+
+	actual, err = lex.AppendKey(buf, 42, map[string]int{})
+	assert.NotNil(t, err)
+	assert.Equal(t, buf, actual)
+}
+
+func ExampleAppendKey() {
+	buf := make([]byte, 0, 64)
+	for _, id := range []int16{1, 2} {
+		buf, _ = lex.AppendKey(buf[:0], "user", id)
+		fmt.Println(buf)
+	}
+
+	// Output:
+	// [117 115 101 114 0 128 1]
+	// [117 115 101 114 0 128 2]
+}
+
 //
 
 func TestMustKey_noargs(t *testing.T) {
@@ -316,6 +347,61 @@ func TestMustKey_invalidnilptr(t *testing.T) {
 	})
 }
 
+func TestUnkey(t *testing.T) {
+	k := lex.MustKey("howdy", lex.Desc(int16(-3)), []string{"a", "b"}, testStruct{1, "x", 2.5})
+
+	var (
+		name  string
+		score int16
+		tags  []string
+		ts    testStruct
+	)
+	assert.Nil(t, lex.Unkey(k, &name, lex.Desc(&score), &tags, &ts))
+	assert.Equal(t, "howdy", name)
+	assert.Equal(t, int16(-3), score)
+	assert.Equal(t, []string{"a", "b"}, tags)
+	assert.Equal(t, testStruct{1, "x", 2.5}, ts)
+}
+
+func TestUnkey_errors(t *testing.T) {
+	k := lex.MustKey("howdy", int16(42))
+	var (
+		s string
+		i int16
+		j int64
+	)
+
+	err := lex.Unkey(k, &s, &j)
+	assert.True(t, errors.Is(err, lex.ErrShortBuffer))
+	assert.EqualError(t, err, "lex.Unkey: component 1: lex: short buffer")
+
+	err = lex.Unkey(k, &s)
+	assert.True(t, errors.Is(err, lex.ErrTrailingBytes))
+	assert.EqualError(t, err, "lex.Unkey: 2 bytes after component 0: lex: trailing bytes")
+
+	err = lex.Unkey(k[:3], &s, &i)
+	assert.True(t, errors.Is(err, lex.ErrMissingTerminator))
+
+	assert.NotNil(t, lex.Unkey(k, s, &i))
+	assert.NotNil(t, lex.Unkey(k))
+}
+
+func ExampleUnkey() {
+	k := lex.MustKey("user", int16(42))
+
+	var (
+		prefix string
+		id     int16
+	)
+	if err := lex.Unkey(k, &prefix, &id); err != nil {
+		panic(err)
+	}
+	fmt.Println(prefix, id)
+
+	// Output:
+	// user 42
+}
+
 //
 
 type mystring string
@@ -495,6 +581,1058 @@ func TestSizeReflectPutReflect_invalidnilptr(t *testing.T) {
 
 //
 
+func TestKey_escaped(t *testing.T) {
+	var a1 string = "how\x00dy"
+	var a2 int16 = 42
+
+	n := lex.EscapedStringSize(a1)
+	expected := make([]byte, n+2)
+	lex.PutEscapedString(expected, a1)
+	lex.PutInt16(expected[n:], a2)
+
+	actual, err := lex.Key(lex.Escaped(a1), a2)
+	assert.Nil(t, err)
+	assert.True(t, bytes.Equal(expected, actual))
+}
+
+func TestKey_escapedOrder(t *testing.T) {
+	var tests = []string{"", "\x00", "\x00\x00", "\x01", "a", "a\x00", "a\x00b", "ab"}
+	var prev []byte
+	for _, tt := range tests {
+		k := lex.MustKey(lex.Escaped(tt), int16(-1))
+		if prev != nil {
+			assert.Equal(t, -1, bytes.Compare(prev, k), "%q", tt)
+		}
+		prev = k
+	}
+}
+
+func TestReflect_escaped(t *testing.T) {
+	var expected, actual testStruct = testStruct{42, "hel\x00lo", 12.5}, testStruct{}
+	var s string
+
+	b, err := lex.Key(lex.Escaped(expected))
+	assert.Nil(t, err)
+	assert.Equal(t, len(b), lex.Size(lex.Escaped(expected)))
+
+	assert.Nil(t, lex.Reflect(b, lex.Escaped(&actual)))
+	assert.Equal(t, expected, actual)
+
+	assert.Nil(t, lex.Reflect(b[8:], lex.Escaped(&s)))
+	assert.Equal(t, expected.B, s)
+}
+
+func TestReflect_escapedInvalid(t *testing.T) {
+	var s string
+	err := lex.Reflect([]byte("howdy\x00"), lex.Escaped(&s))
+	assert.NotNil(t, err)
+}
+
+func TestKey_bytes(t *testing.T) {
+	var a1 []byte = []byte{0xDE, 0x00, 0xBE, 0xEF}
+	var a2 int16 = 42
+
+	expected := make([]byte, 9)
+	lex.PutEscapedBytes(expected, a1)
+	lex.PutInt16(expected[7:], a2)
+
+	actual, err := lex.Key(a1, a2)
+	assert.Nil(t, err)
+	assert.True(t, bytes.Equal(expected, actual))
+}
+
+func TestKey_bytesOrder(t *testing.T) {
+	var tests = [][]byte{{}, {0x00}, {0x00, 0x00}, {0x00, 0xFF}, {0x01}, {0x01, 0x00}, {0xFF}}
+	var prev []byte
+	for _, tt := range tests {
+		k := lex.MustKey(tt, int16(-1))
+		if prev != nil {
+			assert.Equal(t, -1, bytes.Compare(prev, k), "%v", tt)
+		}
+		prev = k
+	}
+}
+
+func TestReflect_bytes(t *testing.T) {
+	expected := []byte("how\x00dy")
+	var actual []byte
+
+	b := lex.MustKey(expected, int16(42))
+	assert.Nil(t, lex.Reflect(b, &actual))
+	assert.Equal(t, expected, actual)
+
+	b[0] = 'c'
+	assert.Equal(t, expected, actual) //decoded value is a copy
+}
+
+func TestReflect_bytesView(t *testing.T) {
+	expected := []byte("howdy")
+	var actual []byte
+
+	b := lex.MustKey(expected, int16(42))
+	assert.Nil(t, lex.Reflect(b, lex.View(&actual)))
+	assert.Equal(t, expected, actual)
+
+	b[0] = 'c'
+	assert.Equal(t, []byte("cowdy"), actual) //decoded value shares memory with b
+}
+
+func TestReflect_bytesInvalid(t *testing.T) {
+	var actual []byte
+	err := lex.Reflect([]byte("howdy"), &actual)
+	assert.NotNil(t, err)
+}
+
+func TestKey_array(t *testing.T) {
+	var a1 [3]int32 = [3]int32{-1, 0, 1}
+	var a2 [4]byte = [4]byte{0xDE, 0x00, 0xBE, 0xEF}
+
+	expected := make([]byte, 16)
+	lex.PutInt32(expected, a1[0])
+	lex.PutInt32(expected[4:], a1[1])
+	lex.PutInt32(expected[8:], a1[2])
+	copy(expected[12:], a2[:])
+
+	actual, err := lex.Key(a1, a2)
+	assert.Nil(t, err)
+	assert.True(t, bytes.Equal(expected, actual))
+	assert.Equal(t, 16, lex.Size(a1)+lex.Size(a2))
+}
+
+func TestKey_arrayOrder(t *testing.T) {
+	var tests = [][2]int16{{-2, 5}, {-1, -5}, {-1, 0}, {0, -1}, {1, 1}}
+	var prev []byte
+	for _, tt := range tests {
+		k := lex.MustKey(tt)
+		if prev != nil {
+			assert.Equal(t, -1, bytes.Compare(prev, k), "%v", tt)
+		}
+		prev = k
+	}
+}
+
+func TestReflect_array(t *testing.T) {
+	type digest struct {
+		ID   [16]byte
+		Name [2]string
+	}
+	expected := digest{[16]byte{1, 2, 3, 0, 5}, [2]string{"a", "bc"}}
+	var actual digest
+
+	b := lex.MustKey(expected)
+	assert.Equal(t, 16+2+3, len(b))
+	assert.Nil(t, lex.Reflect(b, &actual))
+	assert.Equal(t, expected, actual)
+}
+
+func TestSizeReflectPutReflect_arrayInvalid(t *testing.T) {
+	var tests = []interface{}{
+		&[0]int{},
+		&[2]map[string]int{},
+	}
+	b := make([]byte, 8)
+	for _, tt := range tests {
+		assert.Equal(t, -1, lex.Size(tt))
+		assert.NotNil(t, lex.PutReflect(b, tt))
+		assert.NotNil(t, lex.Reflect(b, tt))
+	}
+}
+
+func TestKey_slice(t *testing.T) {
+	var a1 []int16 = []int16{-1, 42}
+	var a2 []string = []string{"a", ""}
+
+	expected := []byte{
+		0x01, 0x7F, 0xFF, 0x01, 0x80, 0x2A, 0x00,
+		0x01, 'a', 0x00, 0x01, 0x00, 0x00,
+	}
+
+	actual, err := lex.Key(a1, a2)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, actual)
+	assert.Equal(t, len(expected), lex.Size(a1)+lex.Size(a2))
+}
+
+func TestKey_sliceOrder(t *testing.T) {
+	var tests = [][]string{{}, {""}, {"", ""}, {"", "a"}, {"a"}, {"a", ""}, {"a", "b"}, {"ab"}, {"b"}}
+	var prev []byte
+	for _, tt := range tests {
+		k := lex.MustKey(tt, int16(-1))
+		if prev != nil {
+			assert.Equal(t, -1, bytes.Compare(prev, k), "%q", tt)
+		}
+		prev = k
+	}
+}
+
+func TestReflect_slice(t *testing.T) {
+	type path struct {
+		Tags  []string
+		Steps [][]int64
+		ID    [][]byte
+	}
+	expected := path{
+		[]string{"a", "", "bc"},
+		[][]int64{{1, 2}, {}, {-3}},
+		[][]byte{{0x00}, {}},
+	}
+	var actual path
+
+	b := lex.MustKey(expected)
+	assert.Equal(t, len(b), lex.Size(expected))
+	assert.Nil(t, lex.Reflect(b, &actual))
+	assert.Equal(t, expected, actual)
+}
+
+func TestReflect_sliceInvalid(t *testing.T) {
+	var tests = [][]byte{
+		{},                 //no terminator
+		{0x01, 0x80},       //truncated element
+		{0x02, 0x80, 0x00}, //bad marker
+	}
+	for _, tt := range tests {
+		var actual []int8
+		err := lex.Reflect(tt, &actual)
+		assert.NotNil(t, err, "%v", tt)
+	}
+}
+
+func TestKey_desc(t *testing.T) {
+	var a1 int16 = 42
+	var a2 string = "howdy"
+
+	expected := make([]byte, 8)
+	lex.PutInt16(expected, a1)
+	lex.PutStringDesc(expected[2:], a2)
+
+	actual, err := lex.Key(a1, lex.Desc(a2))
+	assert.Nil(t, err)
+	assert.True(t, bytes.Equal(expected, actual))
+}
+
+func TestKey_descOrder(t *testing.T) {
+	var tests = []struct {
+		A int16
+		B string
+		C []int8
+	}{
+		{-1, "b", []int8{1}},
+		{-1, "b", []int8{}},
+		{-1, "ab", []int8{1}},
+		{-1, "a", []int8{2}},
+		{-1, "", []int8{}},
+		{0, "b", []int8{-1}},
+	}
+	var prev []byte
+	for _, tt := range tests {
+		k := lex.MustKey(tt.A, lex.Desc(tt.B), lex.Desc(tt.C))
+		if prev != nil {
+			assert.Equal(t, -1, bytes.Compare(prev, k), "%v", tt)
+		}
+		prev = k
+	}
+}
+
+type descStruct struct {
+	A int16 `lex:"desc"`
+	B string
+	C []byte `lex:"desc"`
+}
+
+func TestKey_descTag(t *testing.T) {
+	v := descStruct{42, "howdy", []byte{0}}
+
+	expected, _ := lex.Key(lex.Desc(v.A), v.B, lex.Desc(v.C))
+	actual, err := lex.Key(v)
+	assert.Nil(t, err)
+	assert.True(t, bytes.Equal(expected, actual))
+
+	//descending struct reverses each field again
+	expected, _ = lex.Key(v.A, lex.Desc(v.B), v.C)
+	actual, err = lex.Key(lex.Desc(v))
+	assert.Nil(t, err)
+	assert.True(t, bytes.Equal(expected, actual))
+}
+
+func TestReflect_desc(t *testing.T) {
+	expected := descStruct{42, "how\x00dy", []byte{0}}
+
+	var actual descStruct
+	b := lex.MustKey(lex.Desc(lex.Escaped(expected)))
+	assert.Nil(t, lex.Reflect(b, lex.Escaped(lex.Desc(&actual))))
+	assert.Equal(t, expected, actual)
+
+	var s string
+	b = lex.MustKey(lex.Desc("howdy"))
+	assert.Nil(t, lex.Reflect(b, lex.Desc(&s)))
+	assert.Equal(t, "howdy", s)
+}
+
+func TestReflect_descKinds(t *testing.T) {
+	a, s := int16(42), "howdy"
+	d, _ := lex.ParseDecimal("-1.25")
+	var tests = []struct {
+		v    interface{}
+		wrap func(interface{}) interface{}
+	}{
+		{true, nil},
+		{int8(-3), nil},
+		{uint32(7), nil},
+		{-1.5, nil},
+		{complex64(1 + 2i), nil},
+		{"how\x00dy", lex.Escaped},
+		{[]byte{0, 1, 0xFF}, nil},
+		{int64(-300), lex.Varint},
+		{uint16(300), lex.Varint},
+		{int32(-42), lex.Number},
+		{2.5, lex.Number},
+		{"abcdefghi", lex.Memcomparable},
+		{int64(-9), lex.Memcomparable},
+		{-0.5, lex.Memcomparable},
+		{time.Unix(1, 2).UTC(), nil},
+		{*big.NewInt(-300), nil},
+		{d, nil},
+		{[]string{"a", "", "b"}, nil},
+		{[2]int16{-1, 1}, nil},
+		{&a, nil},
+		{descStruct{42, "how", []byte{0}}, nil},
+		{nullsStruct{&a, &s, 3}, nil},
+		{nullsStruct{nil, nil, 4}, nil},
+	}
+	for _, tt := range tests {
+		wrap := tt.wrap
+		if wrap == nil {
+			wrap = func(v interface{}) interface{} { return v }
+		}
+		b := lex.MustKey(lex.Desc(wrap(tt.v)), int16(-1))
+
+		p := reflect.New(reflect.TypeOf(tt.v))
+		d := lex.NewDecoder(b)
+		d.Reflect(lex.Desc(wrap(p.Interface())))
+		assert.Equal(t, int16(-1), d.Int16(), "%v", tt.v)
+		assert.Nil(t, d.Err(), "%v", tt.v)
+		assert.Equal(t, tt.v, p.Elem().Interface())
+	}
+}
+
+func TestReflect_descAllocs(t *testing.T) {
+	//descending values are read in place, however long the key
+	b := lex.MustKey(lex.Desc(int64(-42)), make([]byte, 1<<16))
+	var i int64
+	p := lex.Desc(&i)
+	allocs := testing.AllocsPerRun(10, func() {
+		lex.NewDecoder(b).Reflect(p)
+	})
+	assert.Equal(t, int64(-42), i)
+	assert.Equal(t, 0.0, allocs)
+}
+
+func intptr(i int) *int {
+	return &i
+}
+
+func TestKey_nulls(t *testing.T) {
+	var p *int
+
+	b, err := lex.Key(lex.NullsFirst(p))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0x00}, b)
+
+	b, err = lex.Key(lex.NullsLast(p))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0x02}, b)
+
+	b, err = lex.Key(lex.NullsFirst(nil))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0x00}, b)
+
+	expected := make([]byte, 9)
+	expected[0] = 0x01
+	lex.PutInt(expected[1:], 42)
+
+	b, err = lex.Key(lex.NullsLast(intptr(42)))
+	assert.Nil(t, err)
+	assert.Equal(t, expected, b)
+	assert.Equal(t, 9, lex.Size(lex.NullsLast(intptr(42))))
+}
+
+func TestKey_nullsOrder(t *testing.T) {
+	var tests = []struct {
+		wrap     func(interface{}) interface{}
+		expected []*int
+	}{
+		{lex.NullsFirst, []*int{nil, intptr(-1), intptr(0), intptr(1)}},
+		{lex.NullsLast, []*int{intptr(-1), intptr(0), intptr(1), nil}},
+		{func(v interface{}) interface{} { return lex.Desc(lex.NullsFirst(v)) }, []*int{nil, intptr(1), intptr(0), intptr(-1)}},
+		{func(v interface{}) interface{} { return lex.Desc(lex.NullsLast(v)) }, []*int{intptr(1), intptr(0), intptr(-1), nil}},
+	}
+	for _, tt := range tests {
+		var prev []byte
+		for _, p := range tt.expected {
+			k := lex.MustKey(tt.wrap(p), int16(-1))
+			if prev != nil {
+				assert.Equal(t, -1, bytes.Compare(prev, k), "%v", p)
+			}
+			prev = k
+		}
+	}
+}
+
+func TestKey_nullsOuterDesc(t *testing.T) {
+	type s struct {
+		A *int `lex:"nullsfirst"`
+	}
+	//an outer Desc inverts the field's null marker, so nil sorts last
+	null, v := lex.MustKey(lex.Desc(s{})), lex.MustKey(lex.Desc(s{intptr(1)}))
+	assert.Equal(t, []byte{0xFF}, null)
+	assert.Equal(t, 1, bytes.Compare(null, v))
+
+	var got s
+	assert.Nil(t, lex.Reflect(null, lex.Desc(&got)))
+	assert.Nil(t, got.A)
+	assert.Nil(t, lex.Reflect(v, lex.Desc(&got)))
+	assert.Equal(t, 1, *got.A)
+}
+
+func TestReflect_nulls(t *testing.T) {
+	var p *int
+
+	b := lex.MustKey(lex.NullsFirst(intptr(42)))
+	assert.Nil(t, lex.Reflect(b, lex.NullsFirst(&p)))
+	assert.Equal(t, 42, *p)
+
+	b = lex.MustKey(lex.NullsFirst(nil))
+	assert.Nil(t, lex.Reflect(b, lex.NullsFirst(&p)))
+	assert.Nil(t, p)
+
+	var i int
+	assert.NotNil(t, lex.Reflect(b, lex.NullsFirst(&i))) //nil requires a pointer
+	assert.NotNil(t, lex.Reflect(b, lex.NullsLast(&p)))  //mismatched marker
+}
+
+type nullsStruct struct {
+	A *int16  `lex:"nullsfirst"`
+	B *string `lex:"nullslast,desc"`
+	C int8
+}
+
+func TestReflect_nullsTag(t *testing.T) {
+	a, s := int16(42), "howdy"
+	var tests = []nullsStruct{
+		{nil, nil, 1},
+		{&a, nil, 2},
+		{nil, &s, 3},
+		{&a, &s, 4},
+	}
+	for _, expected := range tests {
+		var actual nullsStruct
+		b, err := lex.Key(expected)
+		assert.Nil(t, err)
+		assert.Equal(t, len(b), lex.Size(expected))
+		assert.Nil(t, lex.Reflect(b, &actual))
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestKey_time(t *testing.T) {
+	a1 := time.Date(1969, 7, 20, 20, 17, 40, 42, time.UTC)
+
+	expected := make([]byte, 14)
+	lex.PutTime(expected, a1, time.Nanosecond)
+	lex.PutInt16(expected[12:], 42)
+
+	actual, err := lex.Key(a1, int16(42))
+	assert.Nil(t, err)
+	assert.True(t, bytes.Equal(expected, actual))
+	assert.Equal(t, 12, lex.Size(a1))
+}
+
+func TestReflect_time(t *testing.T) {
+	type event struct {
+		At   time.Time
+		Name string
+	}
+	loc := time.FixedZone("UTC+10", 10*60*60)
+	expected := event{time.Date(1969, 7, 21, 6, 17, 40, 42, loc), "landing"}
+	var actual event
+
+	b := lex.MustKey(expected)
+	assert.Nil(t, lex.Reflect(b, &actual))
+	assert.True(t, expected.At.Equal(actual.At))
+	assert.Equal(t, time.UTC, actual.At.Location())
+	assert.Equal(t, expected.Name, actual.Name)
+}
+
+func TestKey_bigInt(t *testing.T) {
+	a1, _ := new(big.Int).SetString("-340282366920938463463374607431768211456", 10)
+
+	n := lex.BigIntSize(a1)
+	expected := make([]byte, n+2)
+	lex.PutBigInt(expected, a1)
+	lex.PutInt16(expected[n:], 42)
+
+	actual, err := lex.Key(a1, int16(42))
+	assert.Nil(t, err)
+	assert.True(t, bytes.Equal(expected, actual))
+	assert.Equal(t, n, lex.Size(a1))
+	assert.Equal(t, n, lex.Size(*a1))
+}
+
+func TestReflect_bigInt(t *testing.T) {
+	type balance struct {
+		Token  string
+		Amount *big.Int
+		Limit  big.Int
+	}
+	amount, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	expected := balance{"xcd", amount, *big.NewInt(-42)}
+	var actual balance
+
+	b := lex.MustKey(expected)
+	assert.Nil(t, lex.Reflect(b, &actual))
+	assert.Equal(t, expected.Token, actual.Token)
+	assert.Equal(t, 0, expected.Amount.Cmp(actual.Amount))
+	assert.Equal(t, 0, expected.Limit.Cmp(&actual.Limit))
+
+	var x big.Int
+	assert.Nil(t, lex.Reflect(lex.MustKey(amount), &x))
+	assert.Equal(t, 0, amount.Cmp(&x))
+
+	assert.NotNil(t, lex.Reflect([]byte{0xFF}, &x))
+}
+
+func TestKey_decimal(t *testing.T) {
+	a1, _ := lex.ParseDecimal("1.50")
+	a2, _ := lex.ParseDecimal("1.5")
+
+	k1, err := lex.Key(a1, int16(42))
+	assert.Nil(t, err)
+	k2, err := lex.Key(a2, int16(42))
+	assert.Nil(t, err)
+	assert.True(t, bytes.Equal(k1, k2)) //equal regardless of scale
+	assert.Equal(t, len(k1), lex.Size(a1)+2)
+}
+
+func TestReflect_decimal(t *testing.T) {
+	type price struct {
+		SKU    string
+		Amount lex.Decimal
+	}
+	amount, _ := lex.ParseDecimal("-123.4500")
+	expected := price{"abc", amount}
+	var actual price
+
+	b := lex.MustKey(expected)
+	assert.Nil(t, lex.Reflect(b, &actual))
+	assert.Equal(t, expected.SKU, actual.SKU)
+	assert.Equal(t, "-123.45", actual.Amount.String())
+
+	assert.NotNil(t, lex.Reflect([]byte{0xFF}, &actual.Amount))
+}
+
+func TestKey_varint(t *testing.T) {
+	var a1 int64 = -42
+	var a2 uint32 = 42
+
+	expected := []byte{0x80 - 42, 42}
+
+	actual, err := lex.Key(lex.Varint(a1), lex.Varint(a2))
+	assert.Nil(t, err)
+	assert.Equal(t, expected, actual)
+	assert.Equal(t, 1, lex.Size(lex.Varint(a1)))
+}
+
+func TestReflect_varint(t *testing.T) {
+	type counter struct {
+		ID    uint64
+		Delta int16
+		Name  string
+	}
+	expected := counter{300, -300, "hits"}
+	var actual counter
+
+	b := lex.MustKey(lex.Varint(expected))
+	assert.Equal(t, 3+3+5, len(b))
+	assert.Nil(t, lex.Reflect(b, lex.Varint(&actual)))
+	assert.Equal(t, expected, actual)
+
+	var small int8
+	b = lex.MustKey(lex.Varint(300))
+	assert.NotNil(t, lex.Reflect(b, lex.Varint(&small))) //overflow
+}
+
+func TestKey_number(t *testing.T) {
+	b1 := lex.MustKey(lex.Number(int16(5)))
+	b2 := lex.MustKey(lex.Number(uint64(5)))
+	b3 := lex.MustKey(lex.Number(float32(5)))
+	b4 := lex.MustKey(lex.Number(5.5))
+
+	assert.Equal(t, b1, b2)
+	assert.Equal(t, b1, b3)
+	assert.Equal(t, -1, bytes.Compare(b1, b4))
+	assert.Equal(t, len(b4), lex.Size(lex.Number(5.5)))
+}
+
+func TestReflect_number(t *testing.T) {
+	type reading struct {
+		Sensor uint8
+		Value  float32
+		Count  int
+	}
+	expected := reading{7, -2.5, 1 << 40}
+	var actual reading
+
+	b := lex.MustKey(lex.Number(expected))
+	assert.Nil(t, lex.Reflect(b, lex.Number(&actual)))
+	assert.Equal(t, expected, actual)
+
+	var i int32
+	var u uint16
+	var f float64
+	assert.Nil(t, lex.Reflect(lex.MustKey(lex.Number(42.0)), lex.Number(&i)))
+	assert.Equal(t, int32(42), i)
+	assert.Nil(t, lex.Reflect(lex.MustKey(lex.Number(-42)), lex.Number(&f)))
+	assert.Equal(t, -42.0, f)
+	assert.NotNil(t, lex.Reflect(lex.MustKey(lex.Number(42.5)), lex.Number(&i)))  //not an integer
+	assert.NotNil(t, lex.Reflect(lex.MustKey(lex.Number(-42)), lex.Number(&u)))   //negative
+	assert.NotNil(t, lex.Reflect(lex.MustKey(lex.Number(1<<20)), lex.Number(&u))) //overflow
+}
+
+func TestKey_memcomparable(t *testing.T) {
+	var a1 int8 = -1
+	var a2 = "how\x00dy"
+
+	expected := []byte{
+		0x7F, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
+		'h', 'o', 'w', 0x00, 'd', 'y', 0x00, 0x00, 0xFD,
+	}
+
+	actual, err := lex.Key(lex.Memcomparable(a1), lex.Memcomparable(a2))
+	assert.Nil(t, err)
+	assert.Equal(t, expected, actual)
+	assert.Equal(t, 9, lex.Size(lex.Memcomparable("")))
+}
+
+func TestReflect_memcomparable(t *testing.T) {
+	type row struct {
+		ID      uint16
+		Deleted bool
+		Name    string
+		Data    []byte
+		Score   float32
+	}
+	expected := row{42, true, "how\x00dy", []byte{0, 1, 2, 3, 4, 5, 6, 7, 8}, -2.5}
+	var actual row
+
+	b := lex.MustKey(lex.Memcomparable(expected))
+	assert.Equal(t, 8+8+9+18+8, len(b))
+	assert.Nil(t, lex.Reflect(b, lex.Memcomparable(&actual)))
+	assert.Equal(t, expected, actual)
+
+	var small uint8
+	b = lex.MustKey(lex.Memcomparable(uint16(300)))
+	assert.NotNil(t, lex.Reflect(b, lex.Memcomparable(&small))) //overflow
+	assert.NotNil(t, lex.Reflect(b[:7], lex.Memcomparable(&small)))
+}
+
+//priority sorts High before Low, by way of its Marshaler implementation
+type priority string
+
+func (p priority) MarshalLex(dst []byte) []byte {
+	switch p {
+	case "high":
+		return append(dst, 0)
+	case "medium":
+		return append(dst, 1)
+	}
+	return append(dst, 2)
+}
+
+func (p *priority) UnmarshalLex(b []byte) (int, error) {
+	if len(b) == 0 {
+		return 0, errors.New("priority: short buffer")
+	}
+	switch b[0] {
+	case 0:
+		*p = "high"
+	case 1:
+		*p = "medium"
+	case 2:
+		*p = "low"
+	default:
+		return 0, errors.New("priority: invalid")
+	}
+	return 1, nil
+}
+
+//version is encoded as its major and minor numbers, and reports its size
+type version struct {
+	major, minor uint16
+}
+
+func (v *version) MarshalLex(dst []byte) []byte {
+	return append(dst, byte(v.major>>8), byte(v.major), byte(v.minor>>8), byte(v.minor))
+}
+
+func (v *version) LexSize() int {
+	return 4
+}
+
+func (v *version) UnmarshalLex(b []byte) (int, error) {
+	if len(b) < 4 {
+		return 0, errors.New("version: short buffer")
+	}
+	v.major = uint16(b[0])<<8 | uint16(b[1])
+	v.minor = uint16(b[2])<<8 | uint16(b[3])
+	return 4, nil
+}
+
+func TestKey_marshaler(t *testing.T) {
+	actual, err := lex.Key(priority("low"), &version{1, 2}, "x")
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{2, 0, 1, 0, 2, 'x', 0}, actual)
+
+	assert.Equal(t, 1, lex.Size(priority("high")))
+	assert.Equal(t, 4, lex.Size(&version{}))
+
+	high := lex.MustKey(priority("high"))
+	low := lex.MustKey(priority("low"))
+	assert.Equal(t, -1, bytes.Compare(high, low))
+
+	desc := lex.MustKey(lex.Desc(priority("high")))
+	assert.Equal(t, []byte{0xFF}, desc)
+}
+
+//missized reports a size that differs from the encoding appended by MarshalLex
+type missized struct {
+	size, actual int
+}
+
+func (m missized) MarshalLex(dst []byte) []byte {
+	return append(dst, make([]byte, m.actual)...)
+}
+
+func (m missized) LexSize() int {
+	return m.size
+}
+
+func TestKey_marshalerMissized(t *testing.T) {
+	for _, m := range []missized{{1, 3}, {3, 1}} {
+		assert.Equal(t, m.size, lex.Size(m))
+
+		_, err := lex.Key(m, int64(7))
+		assert.NotNil(t, err, "%+v", m)
+		_, err = lex.AppendKey(nil, m, int64(7))
+		assert.NotNil(t, err, "%+v", m)
+		assert.NotNil(t, lex.PutReflect(make([]byte, 16), m), "%+v", m)
+		_, err = lex.Key(struct{ M missized }{m})
+		assert.NotNil(t, err, "%+v", m)
+
+		//the buffer beyond the value is not overwritten
+		b := []byte{0xAB, 0xAB, 0xAB, 0xAB}
+		lex.PutReflect(b[:m.size], m)
+		assert.Equal(t, []byte{0xAB, 0xAB, 0xAB, 0xAB}[m.size:], b[m.size:], "%+v", m)
+	}
+}
+
+func TestReflect_marshaler(t *testing.T) {
+	type task struct {
+		Priority priority
+		Version  version
+		Name     string
+	}
+	expected := task{"medium", version{3, 4}, "howdy"}
+	var actual task
+
+	//fields are addressable via the pointer, so that pointer receivers are found
+	b := lex.MustKey(&expected)
+	assert.Equal(t, []byte{1, 0, 3, 0, 4, 'h', 'o', 'w', 'd', 'y', 0}, b)
+	assert.Nil(t, lex.Reflect(b, &actual))
+	assert.Equal(t, expected, actual)
+
+	var p priority
+	assert.Nil(t, lex.Reflect([]byte{0}, &p))
+	assert.Equal(t, priority("high"), p)
+	assert.NotNil(t, lex.Reflect([]byte{9}, &p))
+
+	var pp *priority
+	assert.Nil(t, lex.Reflect([]byte{0xFD}, lex.Desc(&pp)))
+	assert.Equal(t, priority("low"), *pp)
+}
+
+type taggedStruct struct {
+	Name    string `lex:"pos=1,escaped"`
+	ID      uint32 `lex:"pos=0,varint"`
+	Score   int16  `lex:"desc"`
+	Comment string `lex:"-"`
+}
+
+func TestKey_tags(t *testing.T) {
+	v := taggedStruct{"how\x00dy", 42, 1, "ignored"}
+
+	expected := []byte{
+		42,
+		'h', 'o', 'w', 0x00, 0xFF, 'd', 'y', 0x00, 0x01,
+		0x7F, 0xFE,
+	}
+
+	actual, err := lex.Key(v)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, actual)
+	assert.Equal(t, len(expected), lex.Size(v))
+}
+
+func TestReflect_tags(t *testing.T) {
+	expected := taggedStruct{"how\x00dy", 300, -1, ""}
+	var actual = taggedStruct{Comment: "unchanged"}
+
+	b := lex.MustKey(expected)
+	assert.Nil(t, lex.Reflect(b, &actual))
+	expected.Comment = "unchanged"
+	assert.Equal(t, expected, actual)
+}
+
+type privateStruct struct {
+	Public  string
+	private int32
+	when    time.Time
+	skipped int `lex:"-"`
+}
+
+func TestReflect_unexported(t *testing.T) {
+	when := time.Date(2017, 3, 14, 15, 9, 26, 0, time.UTC)
+	v := privateStruct{"howdy", -42, when, 7}
+
+	//unexported fields are ignored
+	b, err := lex.Key(v)
+	assert.Nil(t, err)
+	assert.Equal(t, lex.MustKey("howdy"), b)
+	assert.Equal(t, b, lex.MustKey(&v))
+	assert.Equal(t, len(b), lex.Size(v))
+
+	actual := privateStruct{private: 1, skipped: 2}
+	assert.Nil(t, lex.Reflect(b, &actual))
+	assert.Equal(t, privateStruct{Public: "howdy", private: 1, skipped: 2}, actual)
+}
+
+func TestKey_positions(t *testing.T) {
+	type positioned struct {
+		A uint8
+		B uint8 `lex:"pos=0"`
+		C uint8
+		D uint8 `lex:"pos=3"`
+		E uint8 `lex:"-"`
+		F uint8
+	}
+	v := positioned{1, 2, 3, 4, 5, 6}
+	b, err := lex.Key(v)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{2, 1, 3, 4, 6}, b)
+
+	var actual positioned
+	assert.Nil(t, lex.Reflect(b, &actual))
+	v.E = 0
+	assert.Equal(t, v, actual)
+}
+
+func TestKey_badtags(t *testing.T) {
+	type badPos struct {
+		A int `lex:"pos=x"`
+	}
+	type allSkipped struct {
+		A int `lex:"-"`
+	}
+	_, err := lex.Key(badPos{})
+	assert.NotNil(t, err)
+	_, err = lex.Key(allSkipped{})
+	assert.NotNil(t, err)
+	assert.NotNil(t, lex.Reflect([]byte{0}, &badPos{}))
+
+	type unknown struct {
+		A int `lex:"dsc"`
+	}
+	type duplicatePos struct {
+		A int `lex:"pos=1"`
+		B int `lex:"pos=1"`
+	}
+	type repeatedPos struct {
+		A int `lex:"pos=0,pos=1"`
+		B int
+	}
+	type outOfRange struct {
+		A int `lex:"pos=1"`
+		B int `lex:"-"`
+	}
+	type taggedUnexported struct {
+		A int
+		b int `lex:"desc"`
+	}
+	type nested struct {
+		A []unknown
+	}
+	var tests = []struct {
+		v     interface{}
+		field string
+	}{
+		{unknown{}, "unknown.A"},
+		{duplicatePos{}, "duplicatePos.B"},
+		{repeatedPos{}, "repeatedPos.A"},
+		{outOfRange{}, "outOfRange.A"},
+		{taggedUnexported{}, "taggedUnexported.b"},
+		{nested{[]unknown{{}}}, "unknown.A"},
+		{lex.Desc(&unknown{}), "unknown.A"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, -1, lex.Size(tt.v), "%T", tt.v)
+
+		_, err := lex.Key(tt.v)
+		if assert.NotNil(t, err, "%T", tt.v) {
+			assert.Contains(t, err.Error(), tt.field)
+		}
+		_, err = lex.AppendKey(nil, tt.v)
+		if assert.NotNil(t, err, "%T", tt.v) {
+			assert.Contains(t, err.Error(), tt.field)
+		}
+		err = lex.PutReflect(make([]byte, 64), tt.v)
+		if assert.NotNil(t, err, "%T", tt.v) {
+			assert.Contains(t, err.Error(), tt.field)
+		}
+	}
+
+	var u unknown
+	err = lex.Reflect(lex.MustKey(0), &u)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "unknown.A")
+	}
+	var n nested
+	err = lex.Reflect([]byte{1, 0, 0, 0, 0, 0, 0, 0, 0, 0}, &n)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "unknown.A")
+	}
+}
+
+type listNode struct {
+	V    int
+	Next *listNode `lex:"nullslast"`
+}
+
+func TestReflect_recursive(t *testing.T) {
+	in := listNode{1, &listNode{2, &listNode{3, nil}}}
+	k, err := lex.Key(in)
+	assert.Nil(t, err)
+	assert.Equal(t, lex.Size(in), len(k))
+
+	var out listNode
+	assert.Nil(t, lex.Reflect(k, &out))
+	assert.Equal(t, in, out)
+
+	short := lex.MustKey(listNode{1, &listNode{2, nil}})
+	assert.True(t, bytes.Compare(k, short) < 0)
+}
+
+func TestKey_concurrent(t *testing.T) {
+	type concurrentStruct struct {
+		A int
+		B string `lex:"desc"`
+		C []uint16
+	}
+	in := concurrentStruct{-1, "b", []uint16{2, 3}}
+	want := lex.MustKey(in.A, lex.Desc(in.B), in.C)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			k, err := lex.Key(in)
+			assert.Nil(t, err)
+			assert.Equal(t, want, k)
+
+			var out concurrentStruct
+			assert.Nil(t, lex.Reflect(k, &out))
+			assert.Equal(t, in, out)
+		}()
+	}
+	wg.Wait()
+}
+
+type corruptStruct struct {
+	A int32
+	B string
+	C []byte `lex:"desc"`
+	D []int16
+	E *int64  `lex:"nullsfirst"`
+	F uint32  `lex:"varint"`
+	G float64 `lex:"number"`
+	H time.Time
+	I *big.Int
+	J lex.Decimal
+	K string `lex:"escaped"`
+	L [2]uint8
+}
+
+func TestReflect_truncated(t *testing.T) {
+	e := int64(-5)
+	d, _ := lex.ParseDecimal("12.5")
+	in := corruptStruct{-1, "a", []byte{0}, []int16{1, 2}, &e, 300, -1.5, time.Unix(1, 2), big.NewInt(-300), d, "b\x00", [2]uint8{3, 4}}
+	k := lex.MustKey(in)
+
+	var out corruptStruct
+	assert.Nil(t, lex.Reflect(k, &out))
+	assert.Equal(t, k, lex.MustKey(out))
+
+	for i := 0; i < len(k); i++ {
+		var out corruptStruct
+		assert.NotPanics(t, func() {
+			assert.NotNil(t, lex.Reflect(k[:i], &out), "%d bytes", i)
+		})
+	}
+}
+
+func TestReflect_errors(t *testing.T) {
+	var i int64
+	assert.Equal(t, lex.ErrShortBuffer, lex.Reflect([]byte{1, 2, 3}, &i))
+	assert.Equal(t, lex.ErrShortBuffer, lex.Reflect([]byte{1, 2, 3}, lex.Desc(&i)))
+
+	var s string
+	assert.Equal(t, lex.ErrMissingTerminator, lex.Reflect(nil, &s))
+	assert.Equal(t, lex.ErrMissingTerminator, lex.Reflect([]byte("howdy"), lex.Escaped(&s)))
+	assert.Equal(t, lex.ErrInvalid, lex.Reflect([]byte{'a', 0x00, 0x02}, lex.Escaped(&s)))
+
+	var ts testStruct
+	assert.Equal(t, lex.ErrMissingTerminator, lex.Reflect(lex.MustKey(-1)[:8], &ts)) //no string
+	b := append(lex.MustKey(-1), "a"...)
+	assert.Equal(t, lex.ErrMissingTerminator, lex.Reflect(b, &ts))
+	b = append(b, 0x00)
+	assert.Equal(t, lex.ErrShortBuffer, lex.Reflect(b, &ts)) //no float
+
+	var ss []string
+	assert.Equal(t, lex.ErrMissingTerminator, lex.Reflect([]byte{0x01, 'a', 0x00}, &ss))
+
+	var v version
+	assert.EqualError(t, lex.Reflect([]byte{1}, &v), "version: short buffer")
+}
+
+func TestReflect_corrupt(t *testing.T) {
+	e := int64(-5)
+	in := corruptStruct{1, "a", nil, []int16{1}, &e, 1 << 20, 2.5, time.Unix(1, 2), big.NewInt(7), lex.Decimal{}, "b", [2]uint8{}}
+	k := lex.MustKey(in)
+
+	//corrupt random bytes of a valid key, so that decoding reaches every field
+	r := rand.New(rand.NewSource(1))
+	b := make([]byte, len(k))
+	for i := 0; i < 10000; i++ {
+		copy(b, k)
+		for j := r.Intn(3); j >= 0; j-- {
+			b[r.Intn(len(b))] = byte(r.Intn(256))
+		}
+		var out corruptStruct
+		assert.NotPanics(t, func() { lex.Reflect(b, &out) }, "%x", b)
+	}
+}
+
+//
+
 func BenchmarkSizeString(b *testing.B) {
 	s := "hello world"
 	for n := 0; n < b.N; n++ {
@@ -533,6 +1671,13 @@ func BenchmarkPutReflectInt(b *testing.B) {
 	}
 }
 
+func BenchmarkKeyStruct(b *testing.B) {
+	v := testStruct{}
+	for n := 0; n < b.N; n++ {
+		lex.Key(v)
+	}
+}
+
 func BenchmarkPutInt(b *testing.B) {
 	v := 64
 	bs := make([]byte, 8)
@@ -0,0 +1,72 @@
+package lex
+
+import (
+	"reflect"
+)
+
+//Marshaler is implemented by types that provide their own order-preserving encoding.
+//MarshalLex appends the encoded value to dst and returns the extended slice.
+type Marshaler interface {
+	MarshalLex(dst []byte) []byte
+}
+
+//Unmarshaler is implemented by types that can decode a value encoded by their MarshalLex method.
+//UnmarshalLex reads the value from the start of b, and returns the number of bytes read.
+//Other values may be stored after the encoded value.
+type Unmarshaler interface {
+	UnmarshalLex(b []byte) (int, error)
+}
+
+//Sizer may be implemented by a Marshaler to report the number of bytes MarshalLex would append,
+//so that the value need not be encoded twice by Key.
+//If MarshalLex then appends a different number of bytes, the value is invalid, and Key returns an error.
+type Sizer interface {
+	LexSize() int
+}
+
+var (
+	marshalerType   = reflect.TypeOf((*Marshaler)(nil)).Elem()
+	unmarshalerType = reflect.TypeOf((*Unmarshaler)(nil)).Elem()
+)
+
+//marshaler returns the Marshaler implemented by v or its address, or nil if there is none.
+func marshaler(v reflect.Value) Marshaler {
+	if isNil(v) || !v.CanInterface() {
+		return nil
+	}
+	if v.Type().Implements(marshalerType) {
+		return v.Interface().(Marshaler)
+	}
+	if v.CanAddr() && reflect.PtrTo(v.Type()).Implements(marshalerType) {
+		return v.Addr().Interface().(Marshaler)
+	}
+	return nil
+}
+
+//unmarshaler returns the Unmarshaler implemented by the address of v, or nil if there is none.
+func unmarshaler(v reflect.Value) Unmarshaler {
+	if v.CanAddr() && v.CanInterface() && reflect.PtrTo(v.Type()).Implements(unmarshalerType) {
+		return v.Addr().Interface().(Unmarshaler)
+	}
+	return nil
+}
+
+func marshalerSize(m Marshaler) int {
+	if s, ok := m.(Sizer); ok {
+		return s.LexSize()
+	}
+	return len(m.MarshalLex(nil))
+}
+
+//putMarshaler writes the encoding of m into b, where n is its size as returned by marshalerSize.
+//If MarshalLex appends other than n bytes, such as when LexSize is wrong, putMarshaler returns -1.
+func putMarshaler(b []byte, m Marshaler, n int) int {
+	if n < 0 || n > len(b) {
+		return -1
+	}
+	e := m.MarshalLex(b[:0:n])
+	if len(e) != n {
+		return -1
+	}
+	return copy(b, e)
+}
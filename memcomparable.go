@@ -0,0 +1,117 @@
+package lex
+
+import (
+	"math"
+)
+
+//Memcomparable byte strings, as used by TiDB and MyRocks, are encoded in groups of memGroup bytes,
+//each followed by a marker of memMarker minus the number of padding bytes in the group.
+//The final group is padded with zeros, and is always present, so that a value sorts before any longer value that it is a prefix of.
+const (
+	memGroup  = 8
+	memMarker = 0xFF
+	memPad    = 0x00
+)
+
+//MemcomparableBytesSize returns the number of bytes PutMemcomparableBytes would generate to encode v.
+func MemcomparableBytesSize(v []byte) int {
+	return memcomparableSize(len(v))
+}
+
+func memcomparableSize(n int) int {
+	return (n/memGroup + 1) * (memGroup + 1)
+}
+
+//PutMemcomparableBytes serializes []byte as MemcomparableBytesSize(v) bytes, in the memcomparable format used by TiDB and MyRocks.
+//The value is split into groups of 8 bytes, the last of which is padded with zeros, and each group is followed by 0xFF minus the number of padding bytes.
+//Like PutEscapedBytes, order is preserved for values containing NUL characters.
+func PutMemcomparableBytes(b []byte, v []byte) {
+	j := 0
+	for i := 0; i <= len(v); i += memGroup {
+		n := copy(b[j:j+memGroup], v[i:])
+		for k := n; k < memGroup; k++ {
+			b[j+k] = memPad
+		}
+		b[j+memGroup] = byte(memMarker - (memGroup - n))
+		j += memGroup + 1
+	}
+}
+
+//ScanMemcomparableBytes deserializes []byte from byte slice, returning the value and the number of bytes read.
+//The value is always a copy, and never shares memory with b.
+//If b does not begin with a valid memcomparable value, ScanMemcomparableBytes returns nil and -1.
+func ScanMemcomparableBytes(b []byte) ([]byte, int) {
+	v, n, err := memcomparable(b, 0)
+	if err != nil {
+		return nil, -1
+	}
+	return v, n
+}
+
+//PutMemcomparableBytesDesc serializes []byte as MemcomparableBytesSize(v) bytes, in descending order.
+func PutMemcomparableBytesDesc(b []byte, v []byte) {
+	PutMemcomparableBytes(b, v)
+	invert(b[:MemcomparableBytesSize(v)])
+}
+
+//ScanMemcomparableBytesDesc deserializes []byte from byte slice, in descending order, returning the value and the number of bytes read.
+//If b does not begin with a valid memcomparable value, ScanMemcomparableBytesDesc returns nil and -1.
+func ScanMemcomparableBytesDesc(b []byte) ([]byte, int) {
+	v, n, err := memcomparable(b, 0xFF)
+	if err != nil {
+		return nil, -1
+	}
+	return v, n
+}
+
+//memcomparable decodes a memcomparable value from the start of b, with each byte first combined with mask.
+//It returns ErrShortBuffer if b ends before the final group, or ErrInvalid if a group is malformed.
+func memcomparable(b []byte, mask byte) ([]byte, int, error) {
+	v := make([]byte, 0, len(b))
+	for j := 0; ; j += memGroup + 1 {
+		if j+memGroup >= len(b) {
+			return nil, 0, ErrShortBuffer
+		}
+		pad := memMarker - int(b[j+memGroup]^mask)
+		if pad > memGroup {
+			return nil, 0, ErrInvalid
+		}
+
+		n := memGroup - pad
+		v = appendMasked(v, b[j:j+n], mask)
+		for _, c := range b[j+n : j+memGroup] {
+			if c^mask != memPad {
+				return nil, 0, ErrInvalid
+			}
+		}
+		if pad > 0 {
+			return v, j + memGroup + 1, nil
+		}
+	}
+}
+
+//
+
+//PutMemcomparableFloat64 serializes float64 as 8 bytes, in the memcomparable format used by TiDB and MyRocks.
+//The sign bit is flipped for positive values, and all bits are inverted for negative values, so that order is preserved.
+//Unlike PutFloat64, negative zero is encoded as zero.
+func PutMemcomparableFloat64(b []byte, v float64) {
+	u := math.Float64bits(v)
+	if v >= 0 {
+		u |= 1 << 63
+	} else {
+		u = ^u
+	}
+	PutUint64(b, u)
+}
+
+//MemcomparableFloat64 deserializes float64 from 8 bytes.
+func MemcomparableFloat64(b []byte) float64 {
+	u := Uint64(b)
+	if u&(1<<63) != 0 {
+		u &^= 1 << 63
+	} else {
+		u = ^u
+	}
+	return math.Float64frombits(u)
+}
@@ -0,0 +1,119 @@
+package lex
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"testing/quick"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemcomparableBytes(t *testing.T) {
+	//expected encodings as per TiDB's codec.EncodeBytes
+	var tests = []struct {
+		v        []byte
+		expected []byte
+	}{
+		{[]byte{}, []byte{0, 0, 0, 0, 0, 0, 0, 0, 247}},
+		{[]byte{1, 2, 3}, []byte{1, 2, 3, 0, 0, 0, 0, 0, 250}},
+		{[]byte{1, 2, 3, 0}, []byte{1, 2, 3, 0, 0, 0, 0, 0, 251}},
+		{[]byte{1, 2, 3, 4, 5, 6, 7}, []byte{1, 2, 3, 4, 5, 6, 7, 0, 254}},
+		{[]byte{0, 0, 0, 0, 0, 0, 0, 0}, []byte{0, 0, 0, 0, 0, 0, 0, 0, 255, 0, 0, 0, 0, 0, 0, 0, 0, 247}},
+		{[]byte{1, 2, 3, 4, 5, 6, 7, 8}, []byte{1, 2, 3, 4, 5, 6, 7, 8, 255, 0, 0, 0, 0, 0, 0, 0, 0, 247}},
+		{[]byte{1, 2, 3, 4, 5, 6, 7, 8, 9}, []byte{1, 2, 3, 4, 5, 6, 7, 8, 255, 9, 0, 0, 0, 0, 0, 0, 0, 248}},
+	}
+	for _, tt := range tests {
+		b := make([]byte, MemcomparableBytesSize(tt.v)+1)
+		PutMemcomparableBytes(b, tt.v)
+		assert.Equal(t, tt.expected, b[:len(b)-1])
+
+		v, n := ScanMemcomparableBytes(b)
+		assert.Equal(t, tt.v, v)
+		assert.Equal(t, len(tt.expected), n)
+
+		PutMemcomparableBytesDesc(b, tt.v)
+		v, n = ScanMemcomparableBytesDesc(b)
+		assert.Equal(t, tt.v, v)
+		assert.Equal(t, len(tt.expected), n)
+	}
+}
+
+func TestMemcomparableBytes_RandomCompare(t *testing.T) {
+	f := func(a1, a2 []byte) bool {
+		b1 := make([]byte, MemcomparableBytesSize(a1))
+		PutMemcomparableBytes(b1, a1)
+
+		b2 := make([]byte, MemcomparableBytesSize(a2))
+		PutMemcomparableBytes(b2, a2)
+
+		d1 := make([]byte, MemcomparableBytesSize(a1))
+		PutMemcomparableBytesDesc(d1, a1)
+
+		d2 := make([]byte, MemcomparableBytesSize(a2))
+		PutMemcomparableBytesDesc(d2, a2)
+
+		v1, _ := ScanMemcomparableBytes(b1)
+		expected := bytes.Compare(a1, a2)
+		return bytes.Compare(b1, b2) == expected && bytes.Compare(d1, d2) == -expected && bytes.Equal(v1, a1)
+	}
+	assert.Nil(t, quick.Check(f, nil))
+}
+
+func TestMemcomparableBytes_badformat(t *testing.T) {
+	var tests = [][]byte{
+		nil,
+		{1, 2, 3, 0, 0, 0, 0, 0},      //truncated
+		{1, 2, 3, 0, 0, 0, 0, 0, 246}, //bad marker
+		{1, 2, 3, 0, 0, 0, 0, 1, 250}, //bad padding
+		{1, 2, 3, 4, 5, 6, 7, 8, 255, 9, 0, 0, 0, 0, 0, 0, 0}, //missing final group
+	}
+	for i, b := range tests {
+		v, n := ScanMemcomparableBytes(b)
+		assert.Nil(t, v, "%v", i)
+		assert.Equal(t, -1, n, "%v", i)
+	}
+}
+
+func TestMemcomparableFloat64(t *testing.T) {
+	b := make([]byte, 8)
+	PutMemcomparableFloat64(b, -42)
+	assert.Equal(t, []byte{0x3F, 0xBA, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}, b)
+	assert.Equal(t, -42.0, MemcomparableFloat64(b))
+
+	PutMemcomparableFloat64(b, math.Copysign(0, -1))
+	assert.Equal(t, []byte{0x80, 0, 0, 0, 0, 0, 0, 0}, b)
+	assert.Equal(t, 0.0, MemcomparableFloat64(b))
+}
+
+func TestMemcomparableFloat64_RandomCompare(t *testing.T) {
+	f := func(a1, a2 float64) bool {
+		if math.IsNaN(a1) || math.IsNaN(a2) {
+			return true //skip if NaN
+		}
+
+		b1 := make([]byte, 8)
+		PutMemcomparableFloat64(b1, a1)
+
+		b2 := make([]byte, 8)
+		PutMemcomparableFloat64(b2, a2)
+
+		var expected int
+		switch {
+		case a1 < a2:
+			expected = -1
+		case a1 > a2:
+			expected = +1
+		}
+		return bytes.Compare(b1, b2) == expected && MemcomparableFloat64(b1) == a1
+	}
+	assert.Nil(t, quick.Check(f, nil))
+}
+
+func TestMemcomparable_ZeroAllocs(t *testing.T) {
+	b := make([]byte, 32)
+	v := []byte("howdy, world")
+	assert.Zero(t, testing.AllocsPerRun(1, func() { PutMemcomparableBytes(b, v) }))
+	assert.Zero(t, testing.AllocsPerRun(1, func() { PutMemcomparableFloat64(b, math.Pi) }))
+	assert.Zero(t, testing.AllocsPerRun(1, func() { MemcomparableFloat64(b) }))
+}
@@ -0,0 +1,236 @@
+package lex
+
+import (
+	"math"
+	"math/bits"
+)
+
+//Numbers of any integer, unsigned or floating-point type share a single encoding, beginning with a class marker.
+//Finite non-zero values are normalized to the form ±1.F × 2^E, with E following the marker as per PutInt16,
+//and F following E as groups of 7 bits, each shifted left by one with the low bit set if another group follows.
+//Negative values invert E and F, so that larger magnitudes sort first.
+const (
+	numNegInf = 0x00
+	numNeg    = 0x01
+	numZero   = 0x02
+	numPos    = 0x03
+	numPosInf = 0x04
+	numNaN    = 0x05
+)
+
+//number holds a finite value as a sign and m × 2^s.
+type number struct {
+	neg bool
+	m   uint64
+	s   int
+}
+
+func numberInt64(v int64) number {
+	if v < 0 {
+		return number{true, uint64(-v), 0} //-MinInt64 wraps to 1<<63
+	}
+	return number{false, uint64(v), 0}
+}
+
+func numberFloat64(v float64) number {
+	b := math.Float64bits(v)
+	neg := b>>63 != 0
+	e := int(b>>52) & 0x7FF
+	m := b & (1<<52 - 1)
+	if e == 0 {
+		return number{neg, m, -1074}
+	}
+	return number{neg, m | 1<<52, e - 1075}
+}
+
+//fraction returns E and the groups of F, packed into the low 7×g bits of f.
+func (x number) fraction() (e int, f uint64, g int) {
+	l := bits.Len64(x.m)
+	e = x.s + l - 1
+	f = x.m &^ (1 << uint(l-1))
+	if f == 0 {
+		return e, 0, 1
+	}
+	k := l - 1 - bits.TrailingZeros64(f) //significant bits of F
+	g = (k + 6) / 7
+	f = f >> uint(l-1-k) << uint(7*g-k)
+	return e, f, g
+}
+
+func (x number) size() int {
+	if x.m == 0 {
+		return 1
+	}
+	_, _, g := x.fraction()
+	return 3 + g
+}
+
+func (x number) put(b []byte) int {
+	if x.m == 0 {
+		b[0] = numZero
+		return 1
+	}
+
+	e, f, g := x.fraction()
+	PutInt16(b[1:3], int16(e))
+	for i := 0; i < g; i++ {
+		c := byte(f>>uint(7*(g-1-i))) << 1
+		if i < g-1 {
+			c |= 1
+		}
+		b[3+i] = c
+	}
+	if x.neg {
+		b[0] = numNeg
+		invert(b[1 : 3+g])
+	} else {
+		b[0] = numPos
+	}
+	return 3 + g
+}
+
+//NumberInt64Size returns the number of bytes PutNumberInt64 would generate to encode v.
+func NumberInt64Size(v int64) int {
+	return numberInt64(v).size()
+}
+
+//NumberUint64Size returns the number of bytes PutNumberUint64 would generate to encode v.
+func NumberUint64Size(v uint64) int {
+	return number{false, v, 0}.size()
+}
+
+//NumberFloat64Size returns the number of bytes PutNumberFloat64 would generate to encode v.
+func NumberFloat64Size(v float64) int {
+	if math.IsInf(v, 0) || math.IsNaN(v) {
+		return 1
+	}
+	return numberFloat64(v).size()
+}
+
+//PutNumberInt64 serializes int64 as between 1 and 12 bytes, returning the number of bytes written.
+//The encoding is shared with PutNumberUint64 and PutNumberFloat64, so that equal values have identical encodings regardless of type,
+//and order is preserved across types.
+func PutNumberInt64(b []byte, v int64) int {
+	return numberInt64(v).put(b)
+}
+
+//PutNumberUint64 serializes uint64 as between 1 and 12 bytes, returning the number of bytes written.
+//See PutNumberInt64.
+func PutNumberUint64(b []byte, v uint64) int {
+	return number{false, v, 0}.put(b)
+}
+
+//PutNumberFloat64 serializes float64 as between 1 and 11 bytes, returning the number of bytes written.
+//See PutNumberInt64.
+//Negative zero is encoded as zero, and NaN sorts after all other values.
+func PutNumberFloat64(b []byte, v float64) int {
+	switch {
+	case math.IsNaN(v):
+		b[0] = numNaN
+		return 1
+	case math.IsInf(v, -1):
+		b[0] = numNegInf
+		return 1
+	case math.IsInf(v, +1):
+		b[0] = numPosInf
+		return 1
+	}
+	return numberFloat64(v).put(b)
+}
+
+//ScanNumber deserializes a number from byte slice, returning the value and the number of bytes read.
+//Assumes that other values may be stored after the encoded value.
+//
+//The value is returned as the narrowest type that represents it exactly:
+//int64 for integers within its range, then uint64, then float64.
+//If b does not begin with a valid number, ScanNumber returns nil and -1.
+func ScanNumber(b []byte) (interface{}, int) {
+	v, n, err := readNumber(b, 0)
+	if err != nil {
+		return nil, -1
+	}
+	return v, n
+}
+
+//readNumber is as per ScanNumber, returning ErrShortBuffer if b ends within the number, or ErrInvalid if b does not begin with a valid number.
+//Each byte of b is first combined with mask, so that a mask of 0xFF decodes a descending value.
+func readNumber(b []byte, mask byte) (interface{}, int, error) {
+	if len(b) == 0 {
+		return nil, 0, ErrShortBuffer
+	}
+
+	neg := false
+	switch b[0] ^ mask {
+	case numZero:
+		return int64(0), 1, nil
+	case numNegInf:
+		return math.Inf(-1), 1, nil
+	case numPosInf:
+		return math.Inf(+1), 1, nil
+	case numNaN:
+		return math.NaN(), 1, nil
+	case numPos:
+	case numNeg:
+		neg = true
+		mask ^= 0xFF
+	default:
+		return nil, 0, ErrInvalid
+	}
+	if len(b) < 4 {
+		return nil, 0, ErrShortBuffer
+	}
+
+	e := int(Int16([]byte{b[1] ^ mask, b[2] ^ mask}))
+	var f uint64
+	n := 3
+	for ; ; n++ {
+		if n >= len(b) {
+			return nil, 0, ErrShortBuffer
+		}
+		if n-3 >= 9 {
+			return nil, 0, ErrInvalid
+		}
+		c := b[n] ^ mask
+		f = f<<7 | uint64(c>>1)
+		if c&1 == 0 {
+			break
+		}
+	}
+	n++
+
+	k := 7 * (n - 3)
+	x := number{neg, 1<<uint(k) | f, e - k}
+	v := x.value()
+	if v == nil {
+		return nil, 0, ErrInvalid
+	}
+	return v, n, nil
+}
+
+//value returns x as the narrowest of int64, uint64 or float64 that represents it exactly, or nil if there is none.
+func (x number) value() interface{} {
+	tz := bits.TrailingZeros64(x.m)
+	m, s := x.m>>uint(tz), x.s+tz
+	l := bits.Len64(m)
+
+	if s >= 0 && l+s <= 64 {
+		u := m << uint(s)
+		switch {
+		case !x.neg && u <= math.MaxInt64:
+			return int64(u)
+		case !x.neg:
+			return u
+		case u <= 1<<63:
+			return -int64(u)
+		}
+	}
+
+	if l > 53 || s < -1074 || s+l > 1024 {
+		return nil
+	}
+	f := math.Ldexp(float64(m), s)
+	if x.neg {
+		f = -f
+	}
+	return f
+}
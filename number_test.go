@@ -0,0 +1,192 @@
+package lex
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"testing/quick"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func putNumber(v interface{}) []byte {
+	b := make([]byte, 12)
+	var n int
+	switch v := v.(type) {
+	case int64:
+		n = PutNumberInt64(b, v)
+		if n != NumberInt64Size(v) {
+			panic("size mismatch")
+		}
+	case uint64:
+		n = PutNumberUint64(b, v)
+		if n != NumberUint64Size(v) {
+			panic("size mismatch")
+		}
+	case float64:
+		n = PutNumberFloat64(b, v)
+		if n != NumberFloat64Size(v) {
+			panic("size mismatch")
+		}
+	}
+	return b[:n]
+}
+
+func TestNumber(t *testing.T) {
+	r := []interface{}{
+		math.Inf(-1),
+		-math.MaxFloat64,
+		float64(math.MinInt64) * 2,
+		int64(math.MinInt64),
+		int64(math.MinInt64 + 1),
+		int64(-257),
+		-256.5,
+		int64(-256),
+		int64(-1),
+		-0.5,
+		-math.SmallestNonzeroFloat64,
+		int64(0),
+		math.SmallestNonzeroFloat64,
+		0.1,
+		0.5,
+		int64(1),
+		1.5,
+		int64(2),
+		int64(3),
+		int64(math.MaxInt64 - 1),
+		int64(math.MaxInt64),
+		uint64(math.MaxInt64 + 1),
+		uint64(math.MaxUint64 - 1),
+		uint64(math.MaxUint64),
+		float64(math.MaxUint64) * 2,
+		math.MaxFloat64,
+		math.Inf(+1),
+	}
+	var prev []byte
+	for _, v := range r {
+		b := putNumber(v)
+
+		v1, n := ScanNumber(b)
+		assert.Equal(t, v, v1)
+		assert.Equal(t, len(b), n)
+
+		if prev != nil {
+			assert.Equal(t, -1, bytes.Compare(prev, b), "%v", v)
+		}
+		prev = b
+	}
+}
+
+func TestNumber_crossType(t *testing.T) {
+	//equal values have identical encodings, and decode as the narrowest exact type
+	var tests = []struct {
+		values   []interface{}
+		expected interface{}
+	}{
+		{[]interface{}{int64(5), uint64(5), 5.0}, int64(5)},
+		{[]interface{}{int64(0), uint64(0), 0.0, math.Copysign(0, -1)}, int64(0)},
+		{[]interface{}{int64(-1 << 40), -float64(1 << 40)}, int64(-1 << 40)},
+		{[]interface{}{uint64(1 << 63), float64(1 << 63)}, uint64(1 << 63)},
+		{[]interface{}{float64(1 << 64)}, float64(1 << 64)},
+		{[]interface{}{float64(float32(0.1))}, float64(float32(0.1))},
+	}
+	for _, tt := range tests {
+		b := putNumber(tt.values[0])
+		for _, v := range tt.values[1:] {
+			assert.Equal(t, b, putNumber(v), "%v", v)
+		}
+
+		v, _ := ScanNumber(b)
+		assert.Equal(t, tt.expected, v)
+	}
+}
+
+func TestNumber_NaN(t *testing.T) {
+	b := putNumber(math.NaN())
+	assert.Equal(t, 1, bytes.Compare(b, putNumber(math.Inf(+1))))
+
+	v, n := ScanNumber(b)
+	assert.True(t, math.IsNaN(v.(float64)))
+	assert.Equal(t, 1, n)
+}
+
+func TestNumber_RandomCompare(t *testing.T) {
+	f := func(a1 int64, a2 float64, s uint8) bool {
+		if math.IsNaN(a2) {
+			return true //skip if NaN
+		}
+		a1 >>= s % 64
+
+		b1 := putNumber(a1)
+		b2 := putNumber(a2)
+
+		var expected int
+		switch {
+		case float64(a1) < a2:
+			expected = -1
+		case float64(a1) > a2:
+			expected = +1
+		}
+		if expected == 0 {
+			return true //skip if inexact comparison
+		}
+		v1, _ := ScanNumber(b1)
+		return bytes.Compare(b1, b2) == expected && v1 == a1
+	}
+	assert.Nil(t, quick.Check(f, nil))
+}
+
+func TestNumberFloat64_RandomCompare(t *testing.T) {
+	f := func(a1, a2 float64) bool {
+		if math.IsNaN(a1) || math.IsNaN(a2) {
+			return true //skip if NaN
+		}
+
+		b1 := putNumber(a1)
+		b2 := putNumber(a2)
+
+		var expected int
+		switch {
+		case a1 < a2:
+			expected = -1
+		case a1 > a2:
+			expected = +1
+		}
+		v1, _ := ScanNumber(b1)
+		var f1 float64
+		switch v1 := v1.(type) {
+		case int64:
+			f1 = float64(v1)
+		case uint64:
+			f1 = float64(v1)
+		case float64:
+			f1 = v1
+		}
+		return bytes.Compare(b1, b2) == expected && f1 == a1
+	}
+	assert.Nil(t, quick.Check(f, nil))
+}
+
+func TestNumber_badformat(t *testing.T) {
+	var tests = [][]byte{
+		nil,
+		{0xFF},
+		{numPos, 0x80, 0x00},       //truncated
+		{numPos, 0x80, 0x00, 0x01}, //missing final group
+		{numPos, 0x80, 0x00, 1, 1, 1, 1, 1, 1, 1, 1, 1, 0}, //too many groups
+		{numPos, 0xFF, 0xFF, 0x00},                         //out of range
+		{numNeg, 0x7F, 0xFF, 0xFF ^ 0x03},                  //truncated negative
+	}
+	for i, b := range tests {
+		v, n := ScanNumber(b)
+		assert.Nil(t, v, "%v", i)
+		assert.Equal(t, -1, n, "%v", i)
+	}
+}
+
+func TestNumber_ZeroAllocs(t *testing.T) {
+	b := make([]byte, 12)
+	assert.Zero(t, testing.AllocsPerRun(1, func() { PutNumberInt64(b, math.MinInt32) }))
+	assert.Zero(t, testing.AllocsPerRun(1, func() { PutNumberUint64(b, math.MaxUint32) }))
+	assert.Zero(t, testing.AllocsPerRun(1, func() { PutNumberFloat64(b, math.Pi) }))
+}
@@ -0,0 +1,192 @@
+package lex
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+//opts selects alternative encodings for values passed through the reflection-based functions.
+type opts uint16
+
+const (
+	optEscaped opts = 1 << iota
+	optView
+	optDesc
+	optNullsFirst
+	optNullsLast
+	optVarint
+	optNumber
+	optMemcomparable
+
+	optNullable = optNullsFirst | optNullsLast
+)
+
+//option pairs a value with the encoding options selected for it.
+type option struct {
+	v interface{}
+	o opts
+}
+
+//Escaped marks v for escaped string encoding when passed to Size, PutReflect, Reflect or Key.
+//When v is a struct, the option applies to each of its fields.
+//See PutEscapedString.
+//Struct fields can be marked with the tag `lex:"escaped"`.
+func Escaped(v interface{}) interface{} {
+	return with(v, optEscaped)
+}
+
+//View marks a []byte destination passed to Reflect to share memory with the encoded data where possible.
+//Values containing escaped NUL characters must be unescaped, and so are always copied.
+//The option has no effect on encoding.
+func View(v interface{}) interface{} {
+	return with(v, optView)
+}
+
+//Desc marks v for descending order when passed to Size, PutReflect, Reflect or Key.
+//The encoded value is the ascending encoding with each byte inverted, so Desc can be combined with other options.
+//Struct fields can be marked descending with the tag `lex:"desc"`.
+func Desc(v interface{}) interface{} {
+	return with(v, optDesc)
+}
+
+//NullsFirst marks v as nullable when passed to Size, PutReflect, Reflect or Key.
+//A marker byte is written before the value, such that a nil pointer or nil interface sorts before all other values.
+//Null placement is not affected by Desc on the value itself, as in Desc(NullsFirst(v)) or `lex:"nullsfirst,desc"`.
+//However, Desc on a struct inverts its whole encoding, including the markers of its nullable fields, so an outer Desc reverses their null placement.
+//When passed to Reflect, a pointer to a pointer is set to nil or to a newly allocated value as appropriate.
+//Struct fields can be marked with the tag `lex:"nullsfirst"`.
+func NullsFirst(v interface{}) interface{} {
+	return with(v, optNullsFirst)
+}
+
+//NullsLast marks v as nullable when passed to Size, PutReflect, Reflect or Key.
+//Behaviour is identical to NullsFirst, except that nil sorts after all other values.
+//Struct fields can be marked with the tag `lex:"nullslast"`.
+func NullsLast(v interface{}) interface{} {
+	return with(v, optNullsLast)
+}
+
+//Varint marks integers in v for variable-length encoding when passed to Size, PutReflect, Reflect or Key.
+//See PutVarint and PutUvarint.
+//Struct fields can be marked with the tag `lex:"varint"`.
+func Varint(v interface{}) interface{} {
+	return with(v, optVarint)
+}
+
+//Number marks integers and floats in v for the unified number encoding when passed to Size, PutReflect, Reflect or Key,
+//so that values of different numeric types can be compared. See PutNumberInt64.
+//Struct fields can be marked with the tag `lex:"number"`.
+func Number(v interface{}) interface{} {
+	return with(v, optNumber)
+}
+
+//Memcomparable marks v for the memcomparable format used by TiDB and MyRocks when passed to Size, PutReflect, Reflect or Key.
+//Booleans and integers are widened to 8 bytes as per PutInt64 or PutUint64, floats are encoded as per PutMemcomparableFloat64,
+//and strings and byte slices as per PutMemcomparableBytes.
+//Struct fields can be marked with the tag `lex:"memcomparable"`.
+func Memcomparable(v interface{}) interface{} {
+	return with(v, optMemcomparable)
+}
+
+func with(v interface{}, o opts) interface{} {
+	if w, ok := v.(option); ok {
+		w.o |= o
+		return w
+	}
+	return option{v, o}
+}
+
+func unwrap(v interface{}) (interface{}, opts) {
+	if w, ok := v.(option); ok {
+		return w.v, w.o
+	}
+	return v, 0
+}
+
+//field is a struct field selected for encoding, with the options selected by its lex tag.
+//Pos is the position selected by the tag, or -1 if none.
+type field struct {
+	index int
+	pos   int
+	o     opts
+}
+
+//structFields returns the exported fields of struct type t in encoding order, as selected by their lex tags.
+//Fields tagged `lex:"-"` are skipped, and each field tagged `lex:"pos=N"` is encoded at position N among the encoded fields,
+//counting from zero, with the other fields filling the remaining positions in declaration order.
+//Unexported fields are skipped, and may only be tagged `lex:"-"`.
+//If a tag holds an unknown option, or a position is repeated or out of range, structFields returns an error naming the field.
+func structFields(t reflect.Type) ([]field, error) {
+	fs := make([]field, 0, t.NumField())
+	for i, n := 0, t.NumField(); i < n; i++ {
+		sf := t.Field(i)
+		tag, tagged := sf.Tag.Lookup("lex")
+		if tag == "-" {
+			continue
+		}
+		if sf.PkgPath != "" {
+			if tagged {
+				return nil, fmt.Errorf("lex: unexported field %v.%s cannot be tagged %q", t, sf.Name, tag)
+			}
+			continue
+		}
+
+		f := field{index: i, pos: -1}
+		for _, s := range strings.Split(tag, ",") {
+			switch {
+			case s == "" && tag == "":
+			case s == "desc":
+				f.o |= optDesc
+			case s == "nullsfirst":
+				f.o |= optNullsFirst
+			case s == "nullslast":
+				f.o |= optNullsLast
+			case s == "escaped":
+				f.o |= optEscaped
+			case s == "varint":
+				f.o |= optVarint
+			case s == "number":
+				f.o |= optNumber
+			case s == "memcomparable":
+				f.o |= optMemcomparable
+			case strings.HasPrefix(s, "pos="):
+				p, err := strconv.Atoi(s[len("pos="):])
+				if err != nil || p < 0 || f.pos >= 0 {
+					return nil, fmt.Errorf("lex: invalid position in tag %q of %v.%s", tag, t, sf.Name)
+				}
+				f.pos = p
+			default:
+				return nil, fmt.Errorf("lex: unknown option %q in tag of %v.%s", s, t, sf.Name)
+			}
+		}
+		fs = append(fs, f)
+	}
+
+	ordered := make([]field, len(fs))
+	placed := make([]bool, len(fs))
+	for _, f := range fs {
+		if f.pos < 0 {
+			continue
+		}
+		if f.pos >= len(fs) {
+			return nil, fmt.Errorf("lex: position %d of %v.%s is out of range for %d fields", f.pos, t, t.Field(f.index).Name, len(fs))
+		}
+		if placed[f.pos] {
+			return nil, fmt.Errorf("lex: position %d of %v.%s is already taken", f.pos, t, t.Field(f.index).Name)
+		}
+		ordered[f.pos], placed[f.pos] = f, true
+	}
+	i := 0
+	for _, f := range fs {
+		if f.pos >= 0 {
+			continue
+		}
+		for placed[i] {
+			i++
+		}
+		ordered[i], placed[i] = f, true
+	}
+	return ordered, nil
+}
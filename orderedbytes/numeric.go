@@ -0,0 +1,437 @@
+package orderedbytes
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/xcdb/lex"
+)
+
+//Numeric values are rounded to maxPrecision significant decimal digits, half up, as per HBase's default MathContext.
+const maxPrecision = 31
+
+//varuintSize returns the number of bytes putVaruint would generate to encode v.
+func varuintSize(v uint64) int {
+	switch {
+	case v <= 240:
+		return 1
+	case v <= 2287:
+		return 2
+	case v <= 67823:
+		return 3
+	}
+	n := 4
+	for v >= 1<<(8*uint(n-1)) && n < 9 {
+		n++
+	}
+	return n
+}
+
+//putVaruint writes v as a SQLite4 variable-length integer, returning the number of bytes written.
+func putVaruint(b []byte, v uint64) int {
+	switch {
+	case v <= 240:
+		b[0] = byte(v)
+		return 1
+	case v <= 2287:
+		b[0] = byte((v-240)/256 + 241)
+		b[1] = byte((v - 240) % 256)
+		return 2
+	case v <= 67823:
+		b[0] = 249
+		b[1] = byte((v - 2288) / 256)
+		b[2] = byte((v - 2288) % 256)
+		return 3
+	}
+	n := varuintSize(v)
+	b[0] = byte(250 + n - 4)
+	for i := n - 1; i > 0; i-- {
+		b[i] = byte(v)
+		v >>= 8
+	}
+	return n
+}
+
+//varuint reads a variable-length integer written by putVaruint, each byte of which is first XORed with mask.
+func varuint(b []byte, mask byte) (uint64, int, bool) {
+	if len(b) == 0 {
+		return 0, 0, false
+	}
+	a0 := uint64(b[0] ^ mask)
+	switch {
+	case a0 <= 240:
+		return a0, 1, true
+	case a0 <= 248:
+		if len(b) < 2 {
+			return 0, 0, false
+		}
+		return 240 + 256*(a0-241) + uint64(b[1]^mask), 2, true
+	case a0 == 249:
+		if len(b) < 3 {
+			return 0, 0, false
+		}
+		return 2288 + 256*uint64(b[1]^mask) + uint64(b[2]^mask), 3, true
+	}
+	n := int(a0) - 250 + 4
+	if len(b) < n {
+		return 0, 0, false
+	}
+	var v uint64
+	for _, c := range b[1:n] {
+		v = v<<8 | uint64(c^mask)
+	}
+	return v, n, true
+}
+
+//
+
+//Numeric values are written in the style of SQLite4, normalized to the form 0.M × 100^E, with each base-100 digit X of M written as 2X+1, except for the last, which is written as 2X.
+//Values with E from 1 to 10 are written with E in the header. Larger values are written with E following the header, as per putVaruint.
+//Values smaller than 1 are written with -E following the header, inverted for positive values.
+//Negative values invert M, and the E of large values.
+//
+//numeric holds a non-zero value as ±0.D × 10^X, where D is a sequence of decimal digits with no leading or trailing zeros.
+type numeric struct {
+	neg    bool
+	digits string
+	exp    int
+}
+
+func numericOf(neg bool, digits string, exp int) numeric {
+	t := strings.TrimLeft(digits, "0")
+	exp -= len(digits) - len(t)
+	digits = strings.TrimRight(t, "0")
+
+	if len(digits) > maxPrecision {
+		round := digits[maxPrecision] >= '5'
+		d := []byte(digits[:maxPrecision])
+		for i := len(d) - 1; round && i >= 0; i-- {
+			if d[i] == '9' {
+				d[i] = '0'
+				continue
+			}
+			d[i]++
+			round = false
+		}
+		digits = string(d)
+		if round {
+			digits = "1" + digits
+			exp++
+		}
+		digits = strings.TrimRight(digits, "0")
+	}
+	return numeric{neg, digits, exp}
+}
+
+//centimal returns E and the base-100 digits of M, and whether the value is smaller than 1.
+func (x numeric) centimal() (int, []byte, bool) {
+	var e int
+	small := x.exp <= 0
+	if small {
+		e = -x.exp / 2 //multiply by 100 until 0.01 or more
+	} else {
+		e = (x.exp + 1) / 2
+	}
+
+	s := x.digits
+	if (x.exp+2*e)%2 != 0 {
+		s = "0" + s
+	}
+	if len(s)%2 != 0 {
+		s += "0"
+	}
+	m := make([]byte, len(s)/2)
+	for i := range m {
+		m[i] = (s[2*i]-'0')*10 + s[2*i+1] - '0'
+	}
+	return e, m, small
+}
+
+func (x numeric) size() int {
+	e, m, small := x.centimal()
+	if small || e > 10 {
+		return 1 + varuintSize(uint64(e)) + len(m)
+	}
+	return 1 + len(m)
+}
+
+func (x numeric) put(b []byte, ord Order) int {
+	e, m, small := x.centimal()
+
+	n := 1
+	switch {
+	case small:
+		if x.neg {
+			b[0] = negSmall
+		} else {
+			b[0] = posSmall
+		}
+		k := putVaruint(b[n:], uint64(e))
+		if !x.neg {
+			apply(b[n:n+k], Descending)
+		}
+		n += k
+	case e > 10:
+		if x.neg {
+			b[0] = negLarge
+		} else {
+			b[0] = posLarge
+		}
+		k := putVaruint(b[n:], uint64(e))
+		if x.neg {
+			apply(b[n:n+k], Descending)
+		}
+		n += k
+	case x.neg:
+		b[0] = byte(negMedMax - e)
+	default:
+		b[0] = byte(posMedMin + e)
+	}
+
+	start := n
+	for _, d := range m {
+		b[n] = 2*d + 1
+		n++
+	}
+	b[n-1] &= 0xFE
+	if x.neg {
+		apply(b[start:n], Descending)
+	}
+
+	apply(b[:n], ord)
+	return n
+}
+
+func numericInt64(v int64) numeric {
+	u := uint64(v)
+	if v < 0 {
+		u = uint64(-v) //-MinInt64 wraps to 1<<63
+	}
+	digits := strconv.FormatUint(u, 10)
+	return numericOf(v < 0, digits, len(digits))
+}
+
+func numericFloat64(v float64) numeric {
+	//shortest representation, as per Java's BigDecimal.valueOf(double)
+	s := strconv.FormatFloat(math.Abs(v), 'e', -1, 64)
+	i := strings.IndexByte(s, 'e')
+	exp, _ := strconv.Atoi(s[i+1:])
+	return numericOf(v < 0, strings.Replace(s[:i], ".", "", 1), exp+1)
+}
+
+func numericDecimal(d lex.Decimal) numeric {
+	digits := new(big.Int).Abs(d.Unscaled).String()
+	return numericOf(d.Unscaled.Sign() < 0, digits, len(digits)-int(d.Scale))
+}
+
+//
+
+//NumericInt64Size returns the number of bytes PutNumericInt64 would generate to encode v.
+func NumericInt64Size(v int64) int {
+	if v == 0 {
+		return 1
+	}
+	return numericInt64(v).size()
+}
+
+//PutNumericInt64 serializes int64 in order ord as NumericInt64Size(v) bytes, returning the number of bytes written.
+//The encoding is shared with PutNumericFloat64 and PutNumericDecimal, so that order is preserved across types.
+func PutNumericInt64(b []byte, v int64, ord Order) int {
+	if v == 0 {
+		b[0] = zero ^ byte(ord)
+		return 1
+	}
+	return numericInt64(v).put(b, ord)
+}
+
+//NumericFloat64Size returns the number of bytes PutNumericFloat64 would generate to encode v.
+func NumericFloat64Size(v float64) int {
+	if v == 0 || math.IsInf(v, 0) || math.IsNaN(v) {
+		return 1
+	}
+	return numericFloat64(v).size()
+}
+
+//PutNumericFloat64 serializes float64 in order ord as NumericFloat64Size(v) bytes, returning the number of bytes written.
+//The value is encoded as its shortest decimal representation, so that it is equal to the Decimal with the same digits.
+//NaN sorts after all other values in ascending order.
+func PutNumericFloat64(b []byte, v float64, ord Order) int {
+	switch {
+	case v == 0:
+		b[0] = zero ^ byte(ord)
+		return 1
+	case math.IsNaN(v):
+		b[0] = nan ^ byte(ord)
+		return 1
+	case math.IsInf(v, -1):
+		b[0] = negInf ^ byte(ord)
+		return 1
+	case math.IsInf(v, +1):
+		b[0] = posInf ^ byte(ord)
+		return 1
+	}
+	return numericFloat64(v).put(b, ord)
+}
+
+//NumericDecimalSize returns the number of bytes PutNumericDecimal would generate to encode d.
+func NumericDecimalSize(d lex.Decimal) int {
+	if d.Unscaled == nil || d.Unscaled.Sign() == 0 {
+		return 1
+	}
+	return numericDecimal(d).size()
+}
+
+//PutNumericDecimal serializes Decimal in order ord as NumericDecimalSize(d) bytes, returning the number of bytes written.
+//The value is rounded half up to 31 significant digits.
+func PutNumericDecimal(b []byte, d lex.Decimal, ord Order) int {
+	if d.Unscaled == nil || d.Unscaled.Sign() == 0 {
+		b[0] = zero ^ byte(ord)
+		return 1
+	}
+	return numericDecimal(d).put(b, ord)
+}
+
+//maxExponent is the largest E accepted when reading, so that 100^E can be held by a lex.Decimal with an int32 scale.
+const maxExponent = math.MaxInt32 / 2
+
+//scanNumeric reads a numeric value, returning it and the number of bytes read.
+//Zero is returned with no digits, and special values are instead returned by name, as "NaN", "+Inf" or "-Inf".
+func scanNumeric(b []byte) (numeric, string, int, bool) {
+	h, ord, err := header(b)
+	if err != nil {
+		return numeric{}, "", 0, false
+	}
+
+	var neg, small bool
+	var e, n int
+	switch {
+	case h == zero:
+		return numeric{}, "", 1, true
+	case h == nan:
+		return numeric{}, "NaN", 1, true
+	case h == negInf:
+		return numeric{}, "-Inf", 1, true
+	case h == posInf:
+		return numeric{}, "+Inf", 1, true
+	case h == negLarge, h == posLarge, h == negSmall, h == posSmall:
+		neg = h == negLarge || h == negSmall
+		small = h == negSmall || h == posSmall
+		mask := byte(ord)
+		if (h == negLarge) || (h == posSmall) {
+			mask = ^mask
+		}
+		v, k, ok := varuint(b[1:], mask)
+		if !ok || v > maxExponent {
+			return numeric{}, "", 0, false
+		}
+		e, n = int(v), 1+k
+	case h >= negMedMin && h <= negMedMax:
+		neg, e, n = true, negMedMax-int(h), 1
+	case h >= posMedMin && h <= posMedMax:
+		e, n = int(h)-posMedMin, 1
+	default:
+		return numeric{}, "", 0, false
+	}
+
+	mask := byte(ord)
+	if neg {
+		mask = ^mask
+	}
+	digits := make([]byte, 0, 2*len(b))
+	for ; ; n++ {
+		if n >= len(b) {
+			return numeric{}, "", 0, false
+		}
+		x := b[n] ^ mask
+		if x>>1 > 99 {
+			return numeric{}, "", 0, false
+		}
+		digits = append(digits, '0'+(x>>1)/10, '0'+(x>>1)%10)
+		if x&1 == 0 {
+			break
+		}
+	}
+	n++
+
+	//0.M × 100^E, or 0.M × 100^-E for small values
+	if small {
+		e = -e
+	}
+	t := strings.TrimLeft(string(digits), "0")
+	return numeric{neg, strings.TrimRight(t, "0"), 2*e - (len(digits) - len(t))}, "", n, true
+}
+
+//decimal returns x as a Decimal with the smallest scale that represents it exactly, reporting false if the scale does not fit in an int32.
+func (x numeric) decimal() (lex.Decimal, bool) {
+	if x.digits == "" {
+		return lex.Decimal{Unscaled: new(big.Int)}, true
+	}
+	scale := int64(len(x.digits)) - int64(x.exp)
+	if scale < math.MinInt32 || scale > math.MaxInt32 {
+		return lex.Decimal{}, false
+	}
+	u, _ := new(big.Int).SetString(x.digits, 10)
+	if x.neg {
+		u.Neg(u)
+	}
+	return lex.Decimal{Unscaled: u, Scale: int32(scale)}, true
+}
+
+//NumericDecimal deserializes Decimal from a value written by PutNumericInt64, PutNumericFloat64 or PutNumericDecimal,
+//returning the value and the number of bytes read.
+//The value is returned with the smallest scale that represents it exactly, as per lex.ScanDecimal, so 100 is returned as 1 with a scale of -2.
+//NumericDecimal returns an error for NaN and infinities, which cannot be represented as a Decimal.
+func NumericDecimal(b []byte) (lex.Decimal, int, error) {
+	x, special, n, ok := scanNumeric(b)
+	if ok && special == "" {
+		if d, ok := x.decimal(); ok {
+			return d, n, nil
+		}
+	}
+	return lex.Decimal{}, 0, errors.New("orderedbytes.NumericDecimal: invalid")
+}
+
+//NumericFloat64 deserializes float64 from a value written by PutNumericInt64, PutNumericFloat64 or PutNumericDecimal,
+//returning the nearest float64 and the number of bytes read.
+func NumericFloat64(b []byte) (float64, int, error) {
+	x, s, n, ok := scanNumeric(b)
+	if ok {
+		switch {
+		case s != "":
+		case x.digits == "":
+			s = "0"
+		case x.neg:
+			s = "-0." + x.digits + "e" + strconv.Itoa(x.exp)
+		default:
+			s = "0." + x.digits + "e" + strconv.Itoa(x.exp)
+		}
+		if v, err := strconv.ParseFloat(s, 64); err == nil {
+			return v, n, nil
+		}
+	}
+	return 0, 0, errors.New("orderedbytes.NumericFloat64: invalid")
+}
+
+//NumericInt64 deserializes int64 from a value written by PutNumericInt64, PutNumericFloat64 or PutNumericDecimal,
+//returning the value and the number of bytes read.
+//NumericInt64 returns an error if the value is not an integer within the range of int64.
+func NumericInt64(b []byte) (int64, int, error) {
+	x, special, n, ok := scanNumeric(b)
+	if ok && special == "" && x.digits == "" {
+		return 0, n, nil
+	}
+	//an int64 has at most 19 digits, so larger exponents need not be expanded
+	if ok && special == "" && len(x.digits) <= x.exp && x.exp <= 19 {
+		s := x.digits + strings.Repeat("0", x.exp-len(x.digits))
+		if x.neg {
+			s = "-" + s
+		}
+		if v, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return v, n, nil
+		}
+	}
+	return 0, 0, errors.New("orderedbytes.NumericInt64: invalid")
+}
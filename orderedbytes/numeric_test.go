@@ -0,0 +1,270 @@
+package orderedbytes
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"testing/quick"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xcdb/lex"
+)
+
+func decimal(s string) lex.Decimal {
+	d, err := lex.ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func TestVaruint(t *testing.T) {
+	var tests = []struct {
+		v        uint64
+		expected []byte
+	}{
+		{0, []byte{0x00}},
+		{240, []byte{0xF0}},
+		{241, []byte{0xF1, 0x01}},
+		{2287, []byte{0xF8, 0xFF}},
+		{2288, []byte{0xF9, 0x00, 0x00}},
+		{67823, []byte{0xF9, 0xFF, 0xFF}},
+		{67824, []byte{0xFA, 0x01, 0x08, 0xF0}},
+		{1 << 24, []byte{0xFB, 0x01, 0x00, 0x00, 0x00}},
+		{math.MaxUint64, []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}},
+	}
+	var prev []byte
+	for _, tt := range tests {
+		b := make([]byte, varuintSize(tt.v))
+		assert.Equal(t, len(b), putVaruint(b, tt.v))
+		assert.Equal(t, tt.expected, b, "%v", tt.v)
+
+		v, n, ok := varuint(b, 0)
+		assert.True(t, ok)
+		assert.Equal(t, tt.v, v)
+		assert.Equal(t, len(b), n)
+
+		if prev != nil {
+			assert.Equal(t, -1, bytes.Compare(prev, b), "%v", tt.v)
+		}
+		prev = b
+	}
+}
+
+func TestNumeric(t *testing.T) {
+	var tests = []struct {
+		v        string
+		expected []byte
+	}{
+		{"0", []byte{0x15}},
+		{"1", []byte{0x18, 0x02}},
+		{"-1", []byte{0x12, 0xFD}},
+		{"100", []byte{0x19, 0x02}},
+		{"12.5", []byte{0x18, 0x19, 0x64}},
+		{"0.5", []byte{0x16, 0xFF, 0x64}},
+		{"0.001", []byte{0x16, 0xFE, 0x14}},
+		{"-0.001", []byte{0x14, 0x01, 0xEB}},
+		{"10000000000000000000000000", []byte{0x22, 0x0D, 0x14}},
+		{"-10000000000000000000000000", []byte{0x08, 0xF2, 0xEB}},
+	}
+	for _, tt := range tests {
+		d := decimal(tt.v)
+		b := make([]byte, NumericDecimalSize(d))
+		assert.Equal(t, len(b), PutNumericDecimal(b, d, Ascending))
+		assert.Equal(t, tt.expected, b, "%v", tt.v)
+
+		v, n, err := NumericDecimal(b)
+		assert.Nil(t, err)
+		assert.Equal(t, lex.MustKey(d), lex.MustKey(v), "%v", tt.v) //equal, though the scale may differ
+		assert.Equal(t, len(b), n)
+
+		PutNumericDecimal(b, d, Descending)
+		v, _, err = NumericDecimal(b)
+		assert.Nil(t, err)
+		assert.Equal(t, lex.MustKey(d), lex.MustKey(v), "%v", tt.v)
+	}
+}
+
+func TestNumeric_order(t *testing.T) {
+	r := []interface{}{
+		math.Inf(-1),
+		-math.MaxFloat64,
+		"-1000000000000000000000000000000",
+		int64(math.MinInt64),
+		int64(-100),
+		-99.5,
+		int64(-1),
+		"-0.01",
+		-0.0099,
+		-math.SmallestNonzeroFloat64,
+		int64(0),
+		math.SmallestNonzeroFloat64,
+		0.0099,
+		"0.01",
+		0.5,
+		int64(1),
+		1.01,
+		int64(99),
+		int64(100),
+		int64(math.MaxInt64),
+		"10000000000000000000000",
+		math.MaxFloat64,
+		math.Inf(+1),
+		math.NaN(),
+	}
+	for _, ord := range []Order{Ascending, Descending} {
+		var prev []byte
+		for _, v := range r {
+			b := make([]byte, 32)
+			switch v := v.(type) {
+			case int64:
+				b = b[:PutNumericInt64(b, v, ord)]
+				assert.Equal(t, len(b), NumericInt64Size(v))
+			case float64:
+				b = b[:PutNumericFloat64(b, v, ord)]
+				assert.Equal(t, len(b), NumericFloat64Size(v))
+			case string:
+				d := decimal(v)
+				b = b[:PutNumericDecimal(b, d, ord)]
+				assert.Equal(t, len(b), NumericDecimalSize(d))
+			}
+
+			if prev != nil {
+				expected := -1
+				if ord == Descending {
+					expected = +1
+				}
+				assert.Equal(t, expected, bytes.Compare(prev, b), "%v", v)
+			}
+			prev = b
+		}
+	}
+}
+
+func TestNumeric_crossType(t *testing.T) {
+	b1 := make([]byte, 32)
+	b1 = b1[:PutNumericInt64(b1, 42, Ascending)]
+
+	b2 := make([]byte, 32)
+	b2 = b2[:PutNumericFloat64(b2, 42, Ascending)]
+
+	b3 := make([]byte, 32)
+	b3 = b3[:PutNumericDecimal(b3, decimal("42.000"), Ascending)]
+
+	assert.Equal(t, b1, b2)
+	assert.Equal(t, b1, b3)
+}
+
+func TestNumeric_rounding(t *testing.T) {
+	d := decimal("1.0000000000000000000000000000005") //32 significant digits
+	b := make([]byte, NumericDecimalSize(d))
+	PutNumericDecimal(b, d, Ascending)
+
+	v, _, err := NumericDecimal(b)
+	assert.Nil(t, err)
+	assert.Equal(t, "1.000000000000000000000000000001", v.String())
+
+	d = decimal("9999999999999999999999999999999.5")
+	b = make([]byte, NumericDecimalSize(d))
+	PutNumericDecimal(b, d, Ascending)
+
+	v, _, err = NumericDecimal(b)
+	assert.Nil(t, err)
+	assert.Equal(t, "1E+31", v.String())
+}
+
+func TestNumericInt64_RandomCompare(t *testing.T) {
+	f := func(a1, a2 int64) bool {
+		b1 := make([]byte, NumericInt64Size(a1))
+		PutNumericInt64(b1, a1, Ascending)
+
+		b2 := make([]byte, NumericInt64Size(a2))
+		PutNumericInt64(b2, a2, Ascending)
+
+		var expected int
+		switch {
+		case a1 < a2:
+			expected = -1
+		case a1 > a2:
+			expected = +1
+		}
+		v1, n1, err := NumericInt64(b1)
+		return bytes.Compare(b1, b2) == expected && v1 == a1 && n1 == len(b1) && err == nil
+	}
+	assert.Nil(t, quick.Check(f, nil))
+}
+
+func TestNumericFloat64_RandomCompare(t *testing.T) {
+	f := func(a1, a2 float64) bool {
+		if math.IsNaN(a1) || math.IsNaN(a2) {
+			return true //skip if NaN
+		}
+
+		b1 := make([]byte, NumericFloat64Size(a1))
+		PutNumericFloat64(b1, a1, Descending)
+
+		b2 := make([]byte, NumericFloat64Size(a2))
+		PutNumericFloat64(b2, a2, Descending)
+
+		var expected int
+		switch {
+		case a1 < a2:
+			expected = +1
+		case a1 > a2:
+			expected = -1
+		}
+		v1, n1, err := NumericFloat64(b1)
+		return bytes.Compare(b1, b2) == expected && v1 == a1 && n1 == len(b1) && err == nil
+	}
+	assert.Nil(t, quick.Check(f, nil))
+}
+
+func TestNumeric_special(t *testing.T) {
+	b := make([]byte, 1)
+	PutNumericFloat64(b, math.NaN(), Descending)
+	v, _, err := NumericFloat64(b)
+	assert.Nil(t, err)
+	assert.True(t, math.IsNaN(v))
+
+	_, _, err = NumericDecimal(b)
+	assert.NotNil(t, err)
+	_, _, err = NumericInt64(b)
+	assert.NotNil(t, err)
+
+	b = make([]byte, 32)
+	PutNumericFloat64(b, 0.5, Ascending)
+	_, _, err = NumericInt64(b)
+	assert.NotNil(t, err)
+}
+
+func TestNumeric_largeExponent(t *testing.T) {
+	//exponents within range are not expanded into zeros
+	b := []byte{posLarge, 0xFB, 0x3F, 0xFF, 0xFF, 0xFF, 0x02} //0.01 × 100^(2^30-1)
+	d, n, err := NumericDecimal(b)
+	assert.Nil(t, err)
+	assert.Equal(t, len(b), n)
+	assert.Equal(t, "1E+2147483644", d.String())
+
+	_, _, err = NumericFloat64(b)
+	assert.NotNil(t, err)
+	_, _, err = NumericInt64(b)
+	assert.NotNil(t, err)
+}
+
+func TestNumeric_badformat(t *testing.T) {
+	var tests = [][]byte{
+		nil,
+		{fixedInt8, 0x80},
+		{posMedMin + 1},
+		{posMedMin + 1, 0x03},
+		{posMedMin + 1, 0xFF},
+		{posLarge},
+		{posLarge, 0xF9, 0x00},
+		{posLarge, 0xFB, 0x40, 0x00, 0x00, 0x00, 0x02}, //exponent out of range
+		{posSmall, 0x04, 0xBF, 0xFF, 0xFF, 0xFF, 0x02}, //exponent out of range
+	}
+	for i, b := range tests {
+		_, _, err := NumericDecimal(b)
+		assert.NotNil(t, err, "%v", i)
+	}
+}
@@ -0,0 +1,357 @@
+//Package orderedbytes implements the HBase OrderedBytes encoding, using lex primitives where the formats coincide.
+//
+//Each value is preceded by a header byte identifying its encoding, and may be encoded in either Ascending or Descending order.
+//Descending values are the bytewise inverse of their ascending encoding, including the header, so decoders determine the order from the header.
+//
+//Put functions write to b and return the number of bytes written, while the corresponding decoders return the value, the number of bytes read and an error.
+//Decoders return an error if b does not begin with a value of the expected encoding, including when it begins with a null; use IsNull to check for nulls.
+package orderedbytes
+
+import (
+	"errors"
+	"math"
+	"strings"
+
+	"github.com/xcdb/lex"
+)
+
+//Order is applied to each encoded byte, so that values sort in either ascending or descending order.
+type Order byte
+
+//Orders are masks applied to each byte of the encoding.
+const (
+	Ascending  Order = 0x00
+	Descending Order = 0xFF
+)
+
+//Header bytes, as per org.apache.hadoop.hbase.util.OrderedBytes.
+const (
+	null        = 0x05
+	negInf      = 0x07
+	negLarge    = 0x08
+	negMedMin   = 0x09
+	negMedMax   = 0x13
+	negSmall    = 0x14
+	zero        = 0x15
+	posSmall    = 0x16
+	posMedMin   = 0x17
+	posMedMax   = 0x21
+	posLarge    = 0x22
+	posInf      = 0x23
+	nan         = 0x26
+	fixedInt8   = 0x29
+	fixedInt16  = 0x2A
+	fixedInt32  = 0x2B
+	fixedInt64  = 0x2C
+	fixedFloat  = 0x30
+	fixedDouble = 0x31
+	text        = 0x34
+	blobVar     = 0x37
+	term        = 0x00
+)
+
+func apply(b []byte, ord Order) {
+	if ord == Ascending {
+		return
+	}
+	for i := range b {
+		b[i] ^= byte(ord)
+	}
+}
+
+//order returns the order of an encoded value from its header; all ascending headers are below 0x80.
+func order(h byte) Order {
+	if h&0x80 != 0 {
+		return Descending
+	}
+	return Ascending
+}
+
+//header returns the ascending header of b and its order, or an error if b is empty.
+func header(b []byte) (byte, Order, error) {
+	if len(b) == 0 {
+		return 0, 0, errors.New("orderedbytes: short buffer")
+	}
+	ord := order(b[0])
+	return b[0] ^ byte(ord), ord, nil
+}
+
+//
+
+//PutNull writes a null in order ord as 1 byte, returning the number of bytes written.
+//Nulls sort before all other values in ascending order, and after them in descending order.
+func PutNull(b []byte, ord Order) int {
+	b[0] = null ^ byte(ord)
+	return 1
+}
+
+//IsNull reports whether b begins with a null in either order.
+func IsNull(b []byte) bool {
+	h, _, err := header(b)
+	return err == nil && h == null
+}
+
+//
+
+//BlobVarSize returns the number of bytes PutBlobVar would generate to encode v.
+func BlobVarSize(v []byte) int {
+	if len(v) == 0 {
+		return 2
+	}
+	return 1 + (8*len(v)+6)/7
+}
+
+//PutBlobVar serializes []byte in order ord as BlobVarSize(v) bytes, returning the number of bytes written.
+//The bits of v are written 7 at a time, each group in a byte with the high bit set, except for the last group.
+//An empty slice is written as the header followed by a terminator.
+//As in HBase, order is only preserved between values of equal length, as a cleared high bit may end a shorter value where a longer value continues.
+func PutBlobVar(b []byte, v []byte, ord Order) int {
+	b[0] = blobVar
+	n := 1
+	if len(v) == 0 {
+		b[n] = term
+		n++
+	} else {
+		var acc uint16 //pending bits, aligned to the low end
+		var k uint     //number of pending bits
+		for _, c := range v {
+			acc = acc<<8 | uint16(c)
+			k += 8
+			for k >= 7 {
+				k -= 7
+				b[n] = 0x80 | byte(acc>>k)&0x7F
+				n++
+			}
+		}
+		if k > 0 {
+			b[n] = 0x80 | byte(acc<<(7-k))&0x7F
+			n++
+		}
+		b[n-1] &= 0x7F
+	}
+	apply(b[:n], ord)
+	return n
+}
+
+//BlobVar deserializes []byte from a value written by PutBlobVar, returning the value and the number of bytes read.
+func BlobVar(b []byte) ([]byte, int, error) {
+	h, ord, err := header(b)
+	if err != nil || h != blobVar {
+		return nil, 0, errors.New("orderedbytes.BlobVar: invalid")
+	}
+	if len(b) > 1 && b[1]^byte(ord) == term {
+		return []byte{}, 2, nil
+	}
+
+	v := make([]byte, 0, 7*len(b)/8)
+	var acc uint16
+	var k uint
+	for n := 1; n < len(b); n++ {
+		c := b[n] ^ byte(ord)
+		acc = acc<<7 | uint16(c&0x7F)
+		k += 7
+		if k >= 8 {
+			k -= 8
+			v = append(v, byte(acc>>k))
+		}
+		if c&0x80 == 0 {
+			return v, n + 1, nil
+		}
+	}
+	return nil, 0, errors.New("orderedbytes.BlobVar: invalid")
+}
+
+//
+
+//TextSize returns the number of bytes PutText would generate to encode v, or -1 if v contains a NUL character and so cannot be encoded.
+func TextSize(v string) int {
+	if strings.IndexByte(v, 0x00) >= 0 {
+		return -1
+	}
+	return len(v) + 2
+}
+
+//PutText serializes string in order ord as TextSize(v) bytes, returning the number of bytes written.
+//The bytes of v are written between the header and a terminator, so v must not contain a NUL character.
+//
+//PutText panics if v contains a NUL character.
+func PutText(b []byte, v string, ord Order) int {
+	if TextSize(v) < 0 {
+		panic(errors.New("orderedbytes.PutText: invalid"))
+	}
+	b[0] = text
+	n := 1 + copy(b[1:], v)
+	b[n] = term
+	apply(b[:n+1], ord)
+	return n + 1
+}
+
+//Text deserializes string from a value written by PutText, returning the value and the number of bytes read.
+func Text(b []byte) (string, int, error) {
+	h, ord, err := header(b)
+	if err != nil || h != text {
+		return "", 0, errors.New("orderedbytes.Text: invalid")
+	}
+	for n := 1; n < len(b); n++ {
+		if b[n]^byte(ord) == term {
+			v := make([]byte, n-1)
+			copy(v, b[1:n])
+			apply(v, ord)
+			return string(v), n + 1, nil
+		}
+	}
+	return "", 0, errors.New("orderedbytes.Text: invalid")
+}
+
+//
+
+//fixed checks that b begins with header h followed by n bytes, returning the ascending bytes following the header and their order.
+func fixed(b []byte, h byte, n int) ([]byte, Order, bool) {
+	bh, ord, err := header(b)
+	if err != nil || bh != h || len(b) < 1+n {
+		return nil, 0, false
+	}
+	var t [8]byte
+	copy(t[:n], b[1:1+n])
+	apply(t[:n], ord)
+	return t[:n], ord, true
+}
+
+//PutInt8 serializes int8 in order ord as 2 bytes, returning the number of bytes written.
+//The value follows the header as per lex.PutInt8.
+func PutInt8(b []byte, v int8, ord Order) int {
+	b[0] = fixedInt8
+	lex.PutInt8(b[1:2], v)
+	apply(b[:2], ord)
+	return 2
+}
+
+//Int8 deserializes int8 from a value written by PutInt8, returning the value and the number of bytes read.
+func Int8(b []byte) (int8, int, error) {
+	t, _, ok := fixed(b, fixedInt8, 1)
+	if !ok {
+		return 0, 0, errors.New("orderedbytes.Int8: invalid")
+	}
+	return lex.Int8(t), 2, nil
+}
+
+//PutInt16 serializes int16 in order ord as 3 bytes, returning the number of bytes written.
+//The value follows the header as per lex.PutInt16.
+func PutInt16(b []byte, v int16, ord Order) int {
+	b[0] = fixedInt16
+	lex.PutInt16(b[1:3], v)
+	apply(b[:3], ord)
+	return 3
+}
+
+//Int16 deserializes int16 from a value written by PutInt16, returning the value and the number of bytes read.
+func Int16(b []byte) (int16, int, error) {
+	t, _, ok := fixed(b, fixedInt16, 2)
+	if !ok {
+		return 0, 0, errors.New("orderedbytes.Int16: invalid")
+	}
+	return lex.Int16(t), 3, nil
+}
+
+//PutInt32 serializes int32 in order ord as 5 bytes, returning the number of bytes written.
+//The value follows the header as per lex.PutInt32.
+func PutInt32(b []byte, v int32, ord Order) int {
+	b[0] = fixedInt32
+	lex.PutInt32(b[1:5], v)
+	apply(b[:5], ord)
+	return 5
+}
+
+//Int32 deserializes int32 from a value written by PutInt32, returning the value and the number of bytes read.
+func Int32(b []byte) (int32, int, error) {
+	t, _, ok := fixed(b, fixedInt32, 4)
+	if !ok {
+		return 0, 0, errors.New("orderedbytes.Int32: invalid")
+	}
+	return lex.Int32(t), 5, nil
+}
+
+//PutInt64 serializes int64 in order ord as 9 bytes, returning the number of bytes written.
+//The value follows the header as per lex.PutInt64.
+func PutInt64(b []byte, v int64, ord Order) int {
+	b[0] = fixedInt64
+	lex.PutInt64(b[1:9], v)
+	apply(b[:9], ord)
+	return 9
+}
+
+//Int64 deserializes int64 from a value written by PutInt64, returning the value and the number of bytes read.
+func Int64(b []byte) (int64, int, error) {
+	t, _, ok := fixed(b, fixedInt64, 8)
+	if !ok {
+		return 0, 0, errors.New("orderedbytes.Int64: invalid")
+	}
+	return lex.Int64(t), 9, nil
+}
+
+//PutFloat32 serializes float32 in order ord as 5 bytes, returning the number of bytes written.
+//The IEEE 754 bits follow the header in big-endian, with the sign bit flipped for positive values and all bits inverted for negative values.
+//NaN is written in its canonical form, as per Java's Float.floatToIntBits.
+func PutFloat32(b []byte, v float32, ord Order) int {
+	u := math.Float32bits(v)
+	if v != v {
+		u = 0x7FC00000
+	}
+	if u&(1<<31) != 0 {
+		u = ^u
+	} else {
+		u ^= 1 << 31
+	}
+	b[0] = fixedFloat
+	lex.PutUint32(b[1:5], u)
+	apply(b[:5], ord)
+	return 5
+}
+
+//Float32 deserializes float32 from a value written by PutFloat32, returning the value and the number of bytes read.
+func Float32(b []byte) (float32, int, error) {
+	t, _, ok := fixed(b, fixedFloat, 4)
+	if !ok {
+		return 0, 0, errors.New("orderedbytes.Float32: invalid")
+	}
+	u := lex.Uint32(t)
+	if u&(1<<31) == 0 {
+		u = ^u
+	} else {
+		u ^= 1 << 31
+	}
+	return math.Float32frombits(u), 5, nil
+}
+
+//PutFloat64 serializes float64 in order ord as 9 bytes, returning the number of bytes written.
+//See PutFloat32.
+func PutFloat64(b []byte, v float64, ord Order) int {
+	u := math.Float64bits(v)
+	if v != v {
+		u = 0x7FF8000000000000
+	}
+	if u&(1<<63) != 0 {
+		u = ^u
+	} else {
+		u ^= 1 << 63
+	}
+	b[0] = fixedDouble
+	lex.PutUint64(b[1:9], u)
+	apply(b[:9], ord)
+	return 9
+}
+
+//Float64 deserializes float64 from a value written by PutFloat64, returning the value and the number of bytes read.
+func Float64(b []byte) (float64, int, error) {
+	t, _, ok := fixed(b, fixedDouble, 8)
+	if !ok {
+		return 0, 0, errors.New("orderedbytes.Float64: invalid")
+	}
+	u := lex.Uint64(t)
+	if u&(1<<63) == 0 {
+		u = ^u
+	} else {
+		u ^= 1 << 63
+	}
+	return math.Float64frombits(u), 9, nil
+}
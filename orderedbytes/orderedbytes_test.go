@@ -0,0 +1,237 @@
+package orderedbytes
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"testing/quick"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNull(t *testing.T) {
+	b := make([]byte, 1)
+	assert.Equal(t, 1, PutNull(b, Ascending))
+	assert.Equal(t, []byte{0x05}, b)
+	assert.True(t, IsNull(b))
+
+	PutNull(b, Descending)
+	assert.Equal(t, []byte{0xFA}, b)
+	assert.True(t, IsNull(b))
+
+	assert.False(t, IsNull(nil))
+	assert.False(t, IsNull([]byte{fixedInt8}))
+}
+
+func TestBlobVar(t *testing.T) {
+	var tests = []struct {
+		v        []byte
+		expected []byte
+	}{
+		{[]byte{}, []byte{0x37, 0x00}},
+		{[]byte{0x00}, []byte{0x37, 0x80, 0x00}},
+		{[]byte{0xFF}, []byte{0x37, 0xFF, 0x40}},
+		{[]byte{1, 2, 3, 4, 5, 6, 7}, []byte{0x37, 0x80, 0xC0, 0xC0, 0xB0, 0xA0, 0x94, 0x8C, 0x07}},
+	}
+	for _, tt := range tests {
+		b := make([]byte, BlobVarSize(tt.v))
+		assert.Equal(t, len(b), PutBlobVar(b, tt.v, Ascending))
+		assert.Equal(t, tt.expected, b)
+
+		v, n, err := BlobVar(b)
+		assert.Nil(t, err)
+		assert.Equal(t, tt.v, v)
+		assert.Equal(t, len(b), n)
+
+		PutBlobVar(b, tt.v, Descending)
+		v, n, err = BlobVar(b)
+		assert.Nil(t, err)
+		assert.Equal(t, tt.v, v)
+		assert.Equal(t, len(b), n)
+	}
+}
+
+func TestBlobVar_RandomCompare(t *testing.T) {
+	f := func(a1, a2 []byte) bool {
+		b1 := make([]byte, BlobVarSize(a1)+1)
+		PutBlobVar(b1, a1, Ascending)
+
+		b2 := make([]byte, BlobVarSize(a2)+1)
+		PutBlobVar(b2, a2, Ascending)
+
+		d1 := make([]byte, BlobVarSize(a1)+1)
+		PutBlobVar(d1, a1, Descending)
+
+		d2 := make([]byte, BlobVarSize(a2)+1)
+		PutBlobVar(d2, a2, Descending)
+
+		v1, n1, _ := BlobVar(d1)
+		expected := bytes.Compare(a1, a2)
+		if len(a1) == len(a2) && (bytes.Compare(b1, b2) != expected || bytes.Compare(d1, d2) != -expected) {
+			return false
+		}
+		return bytes.Equal(v1, a1) && n1 == BlobVarSize(a1)
+	}
+	assert.Nil(t, quick.Check(f, nil))
+
+	f2 := func(a1, a2 [5]byte) bool { return f(a1[:], a2[:]) }
+	assert.Nil(t, quick.Check(f2, nil))
+}
+
+func TestBlobVar_lengthOrder(t *testing.T) {
+	//as in HBase, the cleared high bit of the last byte sorts a shorter value before a longer, lesser value
+	a1, a2 := []byte{0xBC, 0xD2}, []byte{0xBD}
+	b1 := make([]byte, BlobVarSize(a1))
+	PutBlobVar(b1, a1, Ascending)
+	b2 := make([]byte, BlobVarSize(a2))
+	PutBlobVar(b2, a2, Ascending)
+	assert.Equal(t, []byte{blobVar, 0xDE, 0xB4, 0x40}, b1)
+	assert.Equal(t, []byte{blobVar, 0xDE, 0x40}, b2)
+	assert.Equal(t, 1, bytes.Compare(b1, b2))
+}
+
+func TestText(t *testing.T) {
+	b := make([]byte, TextSize("howdy")+1)
+	assert.Equal(t, 7, PutText(b, "howdy", Ascending))
+	assert.Equal(t, []byte{0x34, 'h', 'o', 'w', 'd', 'y', 0x00, 0x00}, b)
+
+	v, n, err := Text(b)
+	assert.Nil(t, err)
+	assert.Equal(t, "howdy", v)
+	assert.Equal(t, 7, n)
+
+	PutText(b, "howdy", Descending)
+	assert.Equal(t, []byte{0xCB, ^byte('h'), ^byte('o'), ^byte('w'), ^byte('d'), ^byte('y'), 0xFF}, b[:7])
+	v, n, err = Text(b)
+	assert.Nil(t, err)
+	assert.Equal(t, "howdy", v)
+	assert.Equal(t, 7, n)
+
+	assert.Equal(t, -1, TextSize("how\x00dy"))
+	assert.Panics(t, func() { PutText(make([]byte, 8), "how\x00dy", Ascending) })
+}
+
+func TestText_order(t *testing.T) {
+	r := []string{"", "a", "aa", "b"}
+	var prev, prevDesc []byte
+	for _, v := range r {
+		b := make([]byte, TextSize(v))
+		PutText(b, v, Ascending)
+
+		d := make([]byte, TextSize(v))
+		PutText(d, v, Descending)
+
+		if prev != nil {
+			assert.Equal(t, -1, bytes.Compare(prev, b), "%q", v)
+			assert.Equal(t, 1, bytes.Compare(prevDesc, d), "%q", v)
+		}
+		prev, prevDesc = b, d
+	}
+}
+
+func TestInt(t *testing.T) {
+	b := make([]byte, 9)
+
+	assert.Equal(t, 2, PutInt8(b, -1, Ascending))
+	assert.Equal(t, []byte{0x29, 0x7F}, b[:2])
+	v8, n, err := Int8(b)
+	assert.Nil(t, err)
+	assert.Equal(t, int8(-1), v8)
+	assert.Equal(t, 2, n)
+
+	assert.Equal(t, 3, PutInt16(b, 1, Descending))
+	assert.Equal(t, []byte{0xD5, 0x7F, 0xFE}, b[:3])
+	v16, n, err := Int16(b)
+	assert.Nil(t, err)
+	assert.Equal(t, int16(1), v16)
+	assert.Equal(t, 3, n)
+
+	assert.Equal(t, 5, PutInt32(b, math.MinInt32, Ascending))
+	assert.Equal(t, []byte{0x2B, 0x00, 0x00, 0x00, 0x00}, b[:5])
+	v32, n, err := Int32(b)
+	assert.Nil(t, err)
+	assert.Equal(t, int32(math.MinInt32), v32)
+	assert.Equal(t, 5, n)
+
+	assert.Equal(t, 9, PutInt64(b, math.MaxInt64, Ascending))
+	assert.Equal(t, []byte{0x2C, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}, b)
+	v64, n, err := Int64(b)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(math.MaxInt64), v64)
+	assert.Equal(t, 9, n)
+
+	_, _, err = Int64(b[:8])
+	assert.NotNil(t, err)
+	_, _, err = Int32(b)
+	assert.NotNil(t, err)
+}
+
+func TestInt64_RandomCompare(t *testing.T) {
+	f := func(a1, a2 int64) bool {
+		b1 := make([]byte, 9)
+		PutInt64(b1, a1, Descending)
+
+		b2 := make([]byte, 9)
+		PutInt64(b2, a2, Descending)
+
+		var expected int
+		switch {
+		case a1 < a2:
+			expected = +1
+		case a1 > a2:
+			expected = -1
+		}
+		v1, _, _ := Int64(b1)
+		return bytes.Compare(b1, b2) == expected && v1 == a1
+	}
+	assert.Nil(t, quick.Check(f, nil))
+}
+
+func TestFloat(t *testing.T) {
+	b := make([]byte, 9)
+
+	assert.Equal(t, 5, PutFloat32(b, 42, Ascending))
+	assert.Equal(t, []byte{0x30, 0xC2, 0x28, 0x00, 0x00}, b[:5])
+	v32, n, err := Float32(b)
+	assert.Nil(t, err)
+	assert.Equal(t, float32(42), v32)
+	assert.Equal(t, 5, n)
+
+	PutFloat32(b, float32(math.NaN()), Ascending)
+	assert.Equal(t, []byte{0x30, 0xFF, 0xC0, 0x00, 0x00}, b[:5])
+
+	assert.Equal(t, 9, PutFloat64(b, -42, Ascending))
+	assert.Equal(t, []byte{0x31, 0x3F, 0xBA, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}, b)
+	v64, n, err := Float64(b)
+	assert.Nil(t, err)
+	assert.Equal(t, -42.0, v64)
+	assert.Equal(t, 9, n)
+
+	_, _, err = Float32(b)
+	assert.NotNil(t, err)
+}
+
+func TestFloat64_RandomCompare(t *testing.T) {
+	f := func(a1, a2 float64) bool {
+		if math.IsNaN(a1) || math.IsNaN(a2) {
+			return true //skip if NaN
+		}
+
+		b1 := make([]byte, 9)
+		PutFloat64(b1, a1, Ascending)
+
+		b2 := make([]byte, 9)
+		PutFloat64(b2, a2, Ascending)
+
+		var expected int
+		switch {
+		case a1 < a2:
+			expected = -1
+		case a1 > a2:
+			expected = +1
+		}
+		v1, _, _ := Float64(b1)
+		return bytes.Compare(b1, b2) == expected && v1 == a1
+	}
+	assert.Nil(t, quick.Check(f, nil))
+}
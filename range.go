@@ -0,0 +1,49 @@
+package lex
+
+//Sorted stores iterate over half-open ranges [start, end) most naturally, by seeking to start and stopping at the first key not less than end.
+//The functions below derive such bounds from keys built by Key, without the caller reasoning about the encoding.
+
+//PrefixEnd returns the smallest key greater than every key with the given prefix, for use as the exclusive end of a range.
+//Trailing 0xFF bytes are removed and the last remaining byte incremented, so that the result is never longer than prefix.
+//If prefix is empty or consists only of 0xFF bytes, no such key exists, and PrefixEnd returns nil, denoting a range with no end.
+//
+//For example, as Key(int16(1970)) is a prefix of Key(int16(1970), float32(v)) for every v,
+//the keys for 1970 are exactly those from Key(int16(1970)) up to PrefixEnd(Key(int16(1970))).
+func PrefixEnd(prefix []byte) []byte {
+	for i := len(prefix) - 1; i >= 0; i-- {
+		if prefix[i] != 0xFF {
+			end := make([]byte, i+1)
+			copy(end, prefix)
+			end[i]++
+			return end
+		}
+	}
+	return nil
+}
+
+//KeySuccessor returns the smallest key greater than k, which is k followed by a NUL byte.
+//Used as the exclusive end of a range, it includes k itself, but no other key with k as a prefix.
+func KeySuccessor(k []byte) []byte {
+	s := make([]byte, len(k)+1)
+	copy(s, k)
+	return s
+}
+
+//KeyPredecessor returns the greatest key less than k that is no longer than k, or nil if k is empty.
+//A trailing NUL byte is removed, and any other last byte decremented.
+//As longer keys may fall between the result and k, it is most useful for keys of fixed size, such as those of Numeric values.
+func KeyPredecessor(k []byte) []byte {
+	n := len(k)
+	if n == 0 {
+		return nil
+	}
+	if k[n-1] == 0 {
+		p := make([]byte, n-1)
+		copy(p, k)
+		return p
+	}
+	p := make([]byte, n)
+	copy(p, k)
+	p[n-1]--
+	return p
+}
@@ -0,0 +1,62 @@
+package lex
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrefixEnd(t *testing.T) {
+	assert.Equal(t, []byte{0x01, 0x03}, PrefixEnd([]byte{0x01, 0x02}))
+	assert.Equal(t, []byte{0x02}, PrefixEnd([]byte{0x01, 0xFF, 0xFF}))
+	assert.Nil(t, PrefixEnd([]byte{0xFF, 0xFF}))
+	assert.Nil(t, PrefixEnd(nil))
+
+	//prefix is not modified
+	prefix := []byte{0x01, 0xFF}
+	PrefixEnd(prefix)
+	assert.Equal(t, []byte{0x01, 0xFF}, prefix)
+}
+
+func TestPrefixEnd_range(t *testing.T) {
+	prefix := make([]byte, 2)
+	PutInt16(prefix, 1970)
+	end := PrefixEnd(prefix)
+
+	for _, year := range []int16{1969, 1970, 1971} {
+		for _, rating := range []float32{-1, 0, 9.9} {
+			k := make([]byte, 6)
+			PutInt16(k, year)
+			PutFloat32(k[2:], rating)
+			inRange := bytes.Compare(k, prefix) >= 0 && bytes.Compare(k, end) < 0
+			assert.Equal(t, year == 1970, inRange, "%d %v", year, rating)
+		}
+	}
+}
+
+func TestKeySuccessor(t *testing.T) {
+	k := []byte{0x01, 0xFF}
+	s := KeySuccessor(k)
+	assert.Equal(t, []byte{0x01, 0xFF, 0x00}, s)
+	assert.Equal(t, 1, bytes.Compare(s, k))
+	assert.Equal(t, -1, bytes.Compare(s, []byte{0x01, 0xFF, 0x00, 0x00}))
+	assert.Equal(t, []byte{0x00}, KeySuccessor(nil))
+}
+
+func TestKeyPredecessor(t *testing.T) {
+	assert.Equal(t, []byte{0x01, 0x01}, KeyPredecessor([]byte{0x01, 0x02}))
+	assert.Equal(t, []byte{0x01}, KeyPredecessor([]byte{0x01, 0x00}))
+	assert.Equal(t, []byte{}, KeyPredecessor([]byte{0x00}))
+	assert.Nil(t, KeyPredecessor(nil))
+
+	//inverse of KeySuccessor
+	k := []byte{0x01, 0x02}
+	assert.Equal(t, k, KeyPredecessor(KeySuccessor(k)))
+
+	//fixed-size keys have no others between
+	a, b := make([]byte, 2), make([]byte, 2)
+	PutInt16(a, 41)
+	PutInt16(b, 42)
+	assert.Equal(t, a, KeyPredecessor(b))
+}
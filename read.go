@@ -0,0 +1,528 @@
+package lex
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"time"
+)
+
+//Errors returned by the Read functions, and by Reflect, Decoder and Unkey, when b does not hold a valid encoding.
+var (
+	//ErrShortBuffer is returned when b ends before the value does.
+	ErrShortBuffer = errors.New("lex: short buffer")
+	//ErrMissingTerminator is returned when a string or escaped value is not terminated before the end of b.
+	ErrMissingTerminator = errors.New("lex: missing terminator")
+	//ErrInvalid is returned when b holds bytes that are not a valid encoding of the value.
+	ErrInvalid = errors.New("lex: invalid encoding")
+	//ErrTrailingBytes is returned when bytes remain after the last value of a key.
+	ErrTrailingBytes = errors.New("lex: trailing bytes")
+)
+
+//
+
+//ReadBool deserializes bool from the start of b, as per Bool, returning the value and the number of bytes read.
+//If b is shorter than 1 byte, ReadBool returns ErrShortBuffer.
+func ReadBool(b []byte) (bool, int, error) {
+	if len(b) < 1 {
+		return false, 0, ErrShortBuffer
+	}
+	return Bool(b), 1, nil
+}
+
+//ReadUint8 deserializes uint8 from the start of b, as per Uint8, returning the value and the number of bytes read.
+//If b is shorter than 1 byte, ReadUint8 returns ErrShortBuffer.
+func ReadUint8(b []byte) (uint8, int, error) {
+	if len(b) < 1 {
+		return 0, 0, ErrShortBuffer
+	}
+	return Uint8(b), 1, nil
+}
+
+//ReadUint16 deserializes uint16 from the start of b, as per Uint16, returning the value and the number of bytes read.
+//If b is shorter than 2 bytes, ReadUint16 returns ErrShortBuffer.
+func ReadUint16(b []byte) (uint16, int, error) {
+	if len(b) < 2 {
+		return 0, 0, ErrShortBuffer
+	}
+	return Uint16(b), 2, nil
+}
+
+//ReadUint32 deserializes uint32 from the start of b, as per Uint32, returning the value and the number of bytes read.
+//If b is shorter than 4 bytes, ReadUint32 returns ErrShortBuffer.
+func ReadUint32(b []byte) (uint32, int, error) {
+	if len(b) < 4 {
+		return 0, 0, ErrShortBuffer
+	}
+	return Uint32(b), 4, nil
+}
+
+//ReadUint64 deserializes uint64 from the start of b, as per Uint64, returning the value and the number of bytes read.
+//If b is shorter than 8 bytes, ReadUint64 returns ErrShortBuffer.
+func ReadUint64(b []byte) (uint64, int, error) {
+	if len(b) < 8 {
+		return 0, 0, ErrShortBuffer
+	}
+	return Uint64(b), 8, nil
+}
+
+//ReadInt8 deserializes int8 from the start of b, as per Int8, returning the value and the number of bytes read.
+//If b is shorter than 1 byte, ReadInt8 returns ErrShortBuffer.
+func ReadInt8(b []byte) (int8, int, error) {
+	if len(b) < 1 {
+		return 0, 0, ErrShortBuffer
+	}
+	return Int8(b), 1, nil
+}
+
+//ReadInt16 deserializes int16 from the start of b, as per Int16, returning the value and the number of bytes read.
+//If b is shorter than 2 bytes, ReadInt16 returns ErrShortBuffer.
+func ReadInt16(b []byte) (int16, int, error) {
+	if len(b) < 2 {
+		return 0, 0, ErrShortBuffer
+	}
+	return Int16(b), 2, nil
+}
+
+//ReadInt32 deserializes int32 from the start of b, as per Int32, returning the value and the number of bytes read.
+//If b is shorter than 4 bytes, ReadInt32 returns ErrShortBuffer.
+func ReadInt32(b []byte) (int32, int, error) {
+	if len(b) < 4 {
+		return 0, 0, ErrShortBuffer
+	}
+	return Int32(b), 4, nil
+}
+
+//ReadInt64 deserializes int64 from the start of b, as per Int64, returning the value and the number of bytes read.
+//If b is shorter than 8 bytes, ReadInt64 returns ErrShortBuffer.
+func ReadInt64(b []byte) (int64, int, error) {
+	if len(b) < 8 {
+		return 0, 0, ErrShortBuffer
+	}
+	return Int64(b), 8, nil
+}
+
+//ReadFloat32 deserializes float32 from the start of b, as per Float32, returning the value and the number of bytes read.
+//If b is shorter than 4 bytes, ReadFloat32 returns ErrShortBuffer.
+func ReadFloat32(b []byte) (float32, int, error) {
+	if len(b) < 4 {
+		return 0, 0, ErrShortBuffer
+	}
+	return Float32(b), 4, nil
+}
+
+//ReadFloat64 deserializes float64 from the start of b, as per Float64, returning the value and the number of bytes read.
+//If b is shorter than 8 bytes, ReadFloat64 returns ErrShortBuffer.
+func ReadFloat64(b []byte) (float64, int, error) {
+	if len(b) < 8 {
+		return 0, 0, ErrShortBuffer
+	}
+	return Float64(b), 8, nil
+}
+
+//ReadComplex64 deserializes complex64 from the start of b, as per Complex64, returning the value and the number of bytes read.
+//If b is shorter than 8 bytes, ReadComplex64 returns ErrShortBuffer.
+func ReadComplex64(b []byte) (complex64, int, error) {
+	if len(b) < 8 {
+		return 0, 0, ErrShortBuffer
+	}
+	return Complex64(b), 8, nil
+}
+
+//ReadComplex128 deserializes complex128 from the start of b, as per Complex128, returning the value and the number of bytes read.
+//If b is shorter than 16 bytes, ReadComplex128 returns ErrShortBuffer.
+func ReadComplex128(b []byte) (complex128, int, error) {
+	if len(b) < 16 {
+		return 0, 0, ErrShortBuffer
+	}
+	return Complex128(b), 16, nil
+}
+
+//ReadByte deserializes byte from the start of b, as per Byte, returning the value and the number of bytes read.
+//If b is shorter than 1 byte, ReadByte returns ErrShortBuffer.
+func ReadByte(b []byte) (byte, int, error) {
+	if len(b) < 1 {
+		return 0, 0, ErrShortBuffer
+	}
+	return Byte(b), 1, nil
+}
+
+//ReadRune deserializes rune from the start of b, as per Rune, returning the value and the number of bytes read.
+//If b is shorter than 4 bytes, ReadRune returns ErrShortBuffer.
+func ReadRune(b []byte) (rune, int, error) {
+	if len(b) < 4 {
+		return 0, 0, ErrShortBuffer
+	}
+	return Rune(b), 4, nil
+}
+
+//ReadUint deserializes uint from the start of b, as per Uint, returning the value and the number of bytes read.
+//If b is shorter than 8 bytes, ReadUint returns ErrShortBuffer.
+func ReadUint(b []byte) (uint, int, error) {
+	if len(b) < 8 {
+		return 0, 0, ErrShortBuffer
+	}
+	return Uint(b), 8, nil
+}
+
+//ReadInt deserializes int from the start of b, as per Int, returning the value and the number of bytes read.
+//If b is shorter than 8 bytes, ReadInt returns ErrShortBuffer.
+func ReadInt(b []byte) (int, int, error) {
+	if len(b) < 8 {
+		return 0, 0, ErrShortBuffer
+	}
+	return Int(b), 8, nil
+}
+
+//ReadString deserializes string from the start of b, as per ScanString, returning the value and the number of bytes read.
+//Unlike ScanString, if b does not contain a NUL terminator, ReadString returns ErrMissingTerminator.
+func ReadString(b []byte) (string, int, error) {
+	i := bytes.IndexByte(b, 0)
+	if i < 0 {
+		return "", 0, ErrMissingTerminator
+	}
+	return string(b[:i]), i + 1, nil
+}
+
+//
+
+//ReadBoolDesc deserializes bool from the start of b, as per BoolDesc, returning the value and the number of bytes read.
+//If b is shorter than 1 byte, ReadBoolDesc returns ErrShortBuffer.
+func ReadBoolDesc(b []byte) (bool, int, error) {
+	if len(b) < 1 {
+		return false, 0, ErrShortBuffer
+	}
+	return BoolDesc(b), 1, nil
+}
+
+//ReadUint8Desc deserializes uint8 from the start of b, as per Uint8Desc, returning the value and the number of bytes read.
+//If b is shorter than 1 byte, ReadUint8Desc returns ErrShortBuffer.
+func ReadUint8Desc(b []byte) (uint8, int, error) {
+	if len(b) < 1 {
+		return 0, 0, ErrShortBuffer
+	}
+	return Uint8Desc(b), 1, nil
+}
+
+//ReadUint16Desc deserializes uint16 from the start of b, as per Uint16Desc, returning the value and the number of bytes read.
+//If b is shorter than 2 bytes, ReadUint16Desc returns ErrShortBuffer.
+func ReadUint16Desc(b []byte) (uint16, int, error) {
+	if len(b) < 2 {
+		return 0, 0, ErrShortBuffer
+	}
+	return Uint16Desc(b), 2, nil
+}
+
+//ReadUint32Desc deserializes uint32 from the start of b, as per Uint32Desc, returning the value and the number of bytes read.
+//If b is shorter than 4 bytes, ReadUint32Desc returns ErrShortBuffer.
+func ReadUint32Desc(b []byte) (uint32, int, error) {
+	if len(b) < 4 {
+		return 0, 0, ErrShortBuffer
+	}
+	return Uint32Desc(b), 4, nil
+}
+
+//ReadUint64Desc deserializes uint64 from the start of b, as per Uint64Desc, returning the value and the number of bytes read.
+//If b is shorter than 8 bytes, ReadUint64Desc returns ErrShortBuffer.
+func ReadUint64Desc(b []byte) (uint64, int, error) {
+	if len(b) < 8 {
+		return 0, 0, ErrShortBuffer
+	}
+	return Uint64Desc(b), 8, nil
+}
+
+//ReadInt8Desc deserializes int8 from the start of b, as per Int8Desc, returning the value and the number of bytes read.
+//If b is shorter than 1 byte, ReadInt8Desc returns ErrShortBuffer.
+func ReadInt8Desc(b []byte) (int8, int, error) {
+	if len(b) < 1 {
+		return 0, 0, ErrShortBuffer
+	}
+	return Int8Desc(b), 1, nil
+}
+
+//ReadInt16Desc deserializes int16 from the start of b, as per Int16Desc, returning the value and the number of bytes read.
+//If b is shorter than 2 bytes, ReadInt16Desc returns ErrShortBuffer.
+func ReadInt16Desc(b []byte) (int16, int, error) {
+	if len(b) < 2 {
+		return 0, 0, ErrShortBuffer
+	}
+	return Int16Desc(b), 2, nil
+}
+
+//ReadInt32Desc deserializes int32 from the start of b, as per Int32Desc, returning the value and the number of bytes read.
+//If b is shorter than 4 bytes, ReadInt32Desc returns ErrShortBuffer.
+func ReadInt32Desc(b []byte) (int32, int, error) {
+	if len(b) < 4 {
+		return 0, 0, ErrShortBuffer
+	}
+	return Int32Desc(b), 4, nil
+}
+
+//ReadInt64Desc deserializes int64 from the start of b, as per Int64Desc, returning the value and the number of bytes read.
+//If b is shorter than 8 bytes, ReadInt64Desc returns ErrShortBuffer.
+func ReadInt64Desc(b []byte) (int64, int, error) {
+	if len(b) < 8 {
+		return 0, 0, ErrShortBuffer
+	}
+	return Int64Desc(b), 8, nil
+}
+
+//ReadFloat32Desc deserializes float32 from the start of b, as per Float32Desc, returning the value and the number of bytes read.
+//If b is shorter than 4 bytes, ReadFloat32Desc returns ErrShortBuffer.
+func ReadFloat32Desc(b []byte) (float32, int, error) {
+	if len(b) < 4 {
+		return 0, 0, ErrShortBuffer
+	}
+	return Float32Desc(b), 4, nil
+}
+
+//ReadFloat64Desc deserializes float64 from the start of b, as per Float64Desc, returning the value and the number of bytes read.
+//If b is shorter than 8 bytes, ReadFloat64Desc returns ErrShortBuffer.
+func ReadFloat64Desc(b []byte) (float64, int, error) {
+	if len(b) < 8 {
+		return 0, 0, ErrShortBuffer
+	}
+	return Float64Desc(b), 8, nil
+}
+
+//ReadComplex64Desc deserializes complex64 from the start of b, as per Complex64Desc, returning the value and the number of bytes read.
+//If b is shorter than 8 bytes, ReadComplex64Desc returns ErrShortBuffer.
+func ReadComplex64Desc(b []byte) (complex64, int, error) {
+	if len(b) < 8 {
+		return 0, 0, ErrShortBuffer
+	}
+	return Complex64Desc(b), 8, nil
+}
+
+//ReadComplex128Desc deserializes complex128 from the start of b, as per Complex128Desc, returning the value and the number of bytes read.
+//If b is shorter than 16 bytes, ReadComplex128Desc returns ErrShortBuffer.
+func ReadComplex128Desc(b []byte) (complex128, int, error) {
+	if len(b) < 16 {
+		return 0, 0, ErrShortBuffer
+	}
+	return Complex128Desc(b), 16, nil
+}
+
+//ReadByteDesc deserializes byte from the start of b, as per ByteDesc, returning the value and the number of bytes read.
+//If b is shorter than 1 byte, ReadByteDesc returns ErrShortBuffer.
+func ReadByteDesc(b []byte) (byte, int, error) {
+	if len(b) < 1 {
+		return 0, 0, ErrShortBuffer
+	}
+	return ByteDesc(b), 1, nil
+}
+
+//ReadRuneDesc deserializes rune from the start of b, as per RuneDesc, returning the value and the number of bytes read.
+//If b is shorter than 4 bytes, ReadRuneDesc returns ErrShortBuffer.
+func ReadRuneDesc(b []byte) (rune, int, error) {
+	if len(b) < 4 {
+		return 0, 0, ErrShortBuffer
+	}
+	return RuneDesc(b), 4, nil
+}
+
+//ReadUintDesc deserializes uint from the start of b, as per UintDesc, returning the value and the number of bytes read.
+//If b is shorter than 8 bytes, ReadUintDesc returns ErrShortBuffer.
+func ReadUintDesc(b []byte) (uint, int, error) {
+	if len(b) < 8 {
+		return 0, 0, ErrShortBuffer
+	}
+	return UintDesc(b), 8, nil
+}
+
+//ReadIntDesc deserializes int from the start of b, as per IntDesc, returning the value and the number of bytes read.
+//If b is shorter than 8 bytes, ReadIntDesc returns ErrShortBuffer.
+func ReadIntDesc(b []byte) (int, int, error) {
+	if len(b) < 8 {
+		return 0, 0, ErrShortBuffer
+	}
+	return IntDesc(b), 8, nil
+}
+
+//ReadStringDesc deserializes string from the start of b, in descending order, as per ScanStringDesc, returning the value and the number of bytes read.
+//If b does not contain an inverted NUL terminator, ReadStringDesc returns ErrMissingTerminator.
+func ReadStringDesc(b []byte) (string, int, error) {
+	i := bytes.IndexByte(b, 0xFF)
+	if i < 0 {
+		return "", 0, ErrMissingTerminator
+	}
+	return StringDesc(b[:i+1]), i + 1, nil
+}
+
+//
+
+//ReadEscapedString deserializes string from the start of b, as per ScanEscapedString, returning the value and the number of bytes read.
+//If b ends before the terminator, ReadEscapedString returns ErrMissingTerminator, and if b holds an invalid escape, ErrInvalid.
+func ReadEscapedString(b []byte) (string, int, error) {
+	v, n, err := readEscaped(b, 0)
+	if err != nil {
+		return "", 0, err
+	}
+	return string(v), n, nil
+}
+
+//ReadEscapedStringDesc deserializes string from the start of b, in descending order, as per ScanEscapedStringDesc.
+//Errors are as per ReadEscapedString.
+func ReadEscapedStringDesc(b []byte) (string, int, error) {
+	v, n, err := readEscaped(b, 0xFF)
+	if err != nil {
+		return "", 0, err
+	}
+	return string(v), n, nil
+}
+
+//ReadEscapedBytes deserializes []byte from the start of b, as per ScanEscapedBytes, returning the value and the number of bytes read.
+//The value is always a copy, and never shares memory with b.
+//Errors are as per ReadEscapedString.
+func ReadEscapedBytes(b []byte) ([]byte, int, error) {
+	v, n, err := readEscaped(b, 0)
+	if err != nil {
+		return nil, 0, err
+	}
+	if n == len(v)+2 {
+		//no escaped NULs, so v is a view of b
+		v = append(make([]byte, 0, len(v)), v...)
+	}
+	return v, n, nil
+}
+
+//ReadEscapedBytesDesc deserializes []byte from the start of b, in descending order, as per ScanEscapedBytesDesc.
+//Errors are as per ReadEscapedString.
+func ReadEscapedBytesDesc(b []byte) ([]byte, int, error) {
+	return readEscaped(b, 0xFF)
+}
+
+//
+
+//ReadUvarint deserializes uint64 from the start of b, as per ScanUvarint, returning the value and the number of bytes read.
+//If b is too short, ReadUvarint returns ErrShortBuffer.
+func ReadUvarint(b []byte) (uint64, int, error) {
+	v, n := ScanUvarint(b)
+	if n < 0 {
+		return 0, 0, ErrShortBuffer
+	}
+	return v, n, nil
+}
+
+//ReadVarint deserializes int64 from the start of b, as per ScanVarint, returning the value and the number of bytes read.
+//If b is too short, ReadVarint returns ErrShortBuffer.
+func ReadVarint(b []byte) (int64, int, error) {
+	v, n := ScanVarint(b)
+	if n < 0 {
+		return 0, 0, ErrShortBuffer
+	}
+	return v, n, nil
+}
+
+//ReadNumber deserializes a number from the start of b, as per ScanNumber, returning the value and the number of bytes read.
+//If b ends within the number, ReadNumber returns ErrShortBuffer, and if b does not begin with a valid number, ErrInvalid.
+func ReadNumber(b []byte) (interface{}, int, error) {
+	return readNumber(b, 0)
+}
+
+//ReadNumberDesc deserializes a number from the start of b, encoded in descending order by inverting each byte written by PutNumberInt64 and the like.
+//The bytes are inverted as they are read, so b is not modified. Errors are as per ReadNumber.
+func ReadNumberDesc(b []byte) (interface{}, int, error) {
+	return readNumber(b, 0xFF)
+}
+
+//
+
+//ReadTime deserializes time.Time from the start of b, encoded with precision p, as per Time, returning the value and the number of bytes read.
+//If b is shorter than TimeSize(p), ReadTime returns ErrShortBuffer, and if b holds a time that PutTime could not have written,
+//such as one with a nanosecond field of a second or more, ErrInvalid.
+//
+//ReadTime panics if p is not a supported precision.
+func ReadTime(b []byte, p time.Duration) (time.Time, int, error) {
+	checkPrecision(p)
+	n := TimeSize(p)
+	if len(b) < n {
+		return time.Time{}, 0, ErrShortBuffer
+	}
+	if !timeValid(b, p) {
+		return time.Time{}, 0, ErrInvalid
+	}
+	return Time(b, p), n, nil
+}
+
+//ReadBigInt deserializes *big.Int from the start of b, as per BigInt, returning the value and the number of bytes read.
+//If b ends within the value, ReadBigInt returns ErrShortBuffer, and if b does not begin with a valid sign marker and length, ErrInvalid.
+func ReadBigInt(b []byte) (*big.Int, int, error) {
+	return readBigInt(b, 0)
+}
+
+//readBigInt is as per ReadBigInt, with each byte of b first combined with mask, so that a mask of 0xFF decodes a descending value.
+func readBigInt(b []byte, mask byte) (*big.Int, int, error) {
+	if len(b) == 0 {
+		return nil, 0, ErrShortBuffer
+	}
+	neg := false
+	switch b[0] ^ mask {
+	case bigZero:
+		return new(big.Int), 1, nil
+	case bigPos:
+	case bigNeg:
+		neg = true
+		mask ^= 0xFF
+	default:
+		return nil, 0, ErrInvalid
+	}
+	if len(b) < 5 {
+		return nil, 0, ErrShortBuffer
+	}
+	n := Uint32(b[1:5])
+	if mask != 0 {
+		n = ^n
+	}
+	if n == 0 {
+		return nil, 0, ErrInvalid
+	}
+	if uint64(n) > uint64(len(b)-5) {
+		return nil, 0, ErrShortBuffer
+	}
+
+	m := b[5 : 5+n]
+	if mask != 0 {
+		m = appendMasked(make([]byte, 0, n), m, mask)
+	}
+	x := new(big.Int).SetBytes(m)
+	if neg {
+		x.Neg(x)
+	}
+	return x, 5 + int(n), nil
+}
+
+//ReadDecimal deserializes Decimal from the start of b, as per ScanDecimal, returning the value and the number of bytes read.
+//If b ends within the decimal, ReadDecimal returns ErrShortBuffer, and if b does not begin with a valid decimal, ErrInvalid.
+func ReadDecimal(b []byte) (Decimal, int, error) {
+	return readDecimal(b, 0)
+}
+
+//ReadDecimalDesc deserializes Decimal from the start of b, encoded in descending order by inverting each byte written by PutDecimal.
+//The bytes are inverted as they are read, so b is not modified. Errors are as per ReadDecimal.
+func ReadDecimalDesc(b []byte) (Decimal, int, error) {
+	return readDecimal(b, 0xFF)
+}
+
+//
+
+//ReadMemcomparableBytes deserializes []byte from the start of b, as per ScanMemcomparableBytes, returning the value and the number of bytes read.
+//If b ends before the final group, ReadMemcomparableBytes returns ErrShortBuffer, and if a group is malformed, ErrInvalid.
+func ReadMemcomparableBytes(b []byte) ([]byte, int, error) {
+	return memcomparable(b, 0)
+}
+
+//ReadMemcomparableBytesDesc deserializes []byte from the start of b, in descending order, as per ScanMemcomparableBytesDesc.
+//Errors are as per ReadMemcomparableBytes.
+func ReadMemcomparableBytesDesc(b []byte) ([]byte, int, error) {
+	return memcomparable(b, 0xFF)
+}
+
+//ReadMemcomparableFloat64 deserializes float64 from the start of b, as per MemcomparableFloat64, returning the value and the number of bytes read.
+//If b is shorter than 8 bytes, ReadMemcomparableFloat64 returns ErrShortBuffer.
+func ReadMemcomparableFloat64(b []byte) (float64, int, error) {
+	if len(b) < 8 {
+		return 0, 0, ErrShortBuffer
+	}
+	return MemcomparableFloat64(b), 8, nil
+}
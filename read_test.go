@@ -0,0 +1,111 @@
+package lex
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRead(t *testing.T) {
+	s := "a\x00b"
+	bs := []byte(s)
+	x := big.NewInt(-300)
+	d, _ := ParseDecimal("-1.25")
+	tm := time.Unix(1, 2).UTC()
+	inverted := func(b []byte) []byte {
+		invert(b)
+		return b
+	}
+
+	for i, c := range []struct {
+		b     []byte
+		read  func(b []byte) (interface{}, int, error)
+		want  interface{}
+		short error
+	}{
+		{AppendBool(nil, true), func(b []byte) (interface{}, int, error) { return ReadBool(b) }, true, ErrShortBuffer},
+		{AppendUint8(nil, 42), func(b []byte) (interface{}, int, error) { return ReadUint8(b) }, uint8(42), ErrShortBuffer},
+		{AppendUint16(nil, 42), func(b []byte) (interface{}, int, error) { return ReadUint16(b) }, uint16(42), ErrShortBuffer},
+		{AppendUint32(nil, 42), func(b []byte) (interface{}, int, error) { return ReadUint32(b) }, uint32(42), ErrShortBuffer},
+		{AppendUint64(nil, 42), func(b []byte) (interface{}, int, error) { return ReadUint64(b) }, uint64(42), ErrShortBuffer},
+		{AppendInt8(nil, -42), func(b []byte) (interface{}, int, error) { return ReadInt8(b) }, int8(-42), ErrShortBuffer},
+		{AppendInt16(nil, -42), func(b []byte) (interface{}, int, error) { return ReadInt16(b) }, int16(-42), ErrShortBuffer},
+		{AppendInt32(nil, -42), func(b []byte) (interface{}, int, error) { return ReadInt32(b) }, int32(-42), ErrShortBuffer},
+		{AppendInt64(nil, -42), func(b []byte) (interface{}, int, error) { return ReadInt64(b) }, int64(-42), ErrShortBuffer},
+		{AppendFloat32(nil, -4.2), func(b []byte) (interface{}, int, error) { return ReadFloat32(b) }, float32(-4.2), ErrShortBuffer},
+		{AppendFloat64(nil, -4.2), func(b []byte) (interface{}, int, error) { return ReadFloat64(b) }, -4.2, ErrShortBuffer},
+		{AppendComplex64(nil, 1+2i), func(b []byte) (interface{}, int, error) { return ReadComplex64(b) }, complex64(1 + 2i), ErrShortBuffer},
+		{AppendComplex128(nil, 1+2i), func(b []byte) (interface{}, int, error) { return ReadComplex128(b) }, 1 + 2i, ErrShortBuffer},
+		{AppendByte(nil, 42), func(b []byte) (interface{}, int, error) { return ReadByte(b) }, byte(42), ErrShortBuffer},
+		{AppendRune(nil, 'x'), func(b []byte) (interface{}, int, error) { return ReadRune(b) }, 'x', ErrShortBuffer},
+		{AppendUint(nil, 42), func(b []byte) (interface{}, int, error) { return ReadUint(b) }, uint(42), ErrShortBuffer},
+		{AppendInt(nil, -42), func(b []byte) (interface{}, int, error) { return ReadInt(b) }, -42, ErrShortBuffer},
+		{AppendString(nil, "ab"), func(b []byte) (interface{}, int, error) { return ReadString(b) }, "ab", ErrMissingTerminator},
+		{AppendInt64Desc(nil, -42), func(b []byte) (interface{}, int, error) { return ReadInt64Desc(b) }, int64(-42), ErrShortBuffer},
+		{AppendFloat64Desc(nil, -4.2), func(b []byte) (interface{}, int, error) { return ReadFloat64Desc(b) }, -4.2, ErrShortBuffer},
+		{AppendStringDesc(nil, "ab"), func(b []byte) (interface{}, int, error) { return ReadStringDesc(b) }, "ab", ErrMissingTerminator},
+		{AppendEscapedString(nil, s), func(b []byte) (interface{}, int, error) { return ReadEscapedString(b) }, s, ErrMissingTerminator},
+		{AppendEscapedStringDesc(nil, s), func(b []byte) (interface{}, int, error) { return ReadEscapedStringDesc(b) }, s, ErrMissingTerminator},
+		{AppendEscapedBytes(nil, bs), func(b []byte) (interface{}, int, error) { return ReadEscapedBytes(b) }, bs, ErrMissingTerminator},
+		{AppendEscapedBytesDesc(nil, bs), func(b []byte) (interface{}, int, error) { return ReadEscapedBytesDesc(b) }, bs, ErrMissingTerminator},
+		{AppendUvarint(nil, 300), func(b []byte) (interface{}, int, error) { return ReadUvarint(b) }, uint64(300), ErrShortBuffer},
+		{AppendVarint(nil, -300), func(b []byte) (interface{}, int, error) { return ReadVarint(b) }, int64(-300), ErrShortBuffer},
+		{AppendNumberFloat64(nil, -1.5), func(b []byte) (interface{}, int, error) { return ReadNumber(b) }, -1.5, ErrShortBuffer},
+		{inverted(AppendNumberFloat64(nil, -1.5)), func(b []byte) (interface{}, int, error) { return ReadNumberDesc(b) }, -1.5, ErrShortBuffer},
+		{AppendTime(nil, tm, time.Nanosecond), func(b []byte) (interface{}, int, error) { return ReadTime(b, time.Nanosecond) }, tm, ErrShortBuffer},
+		{AppendBigInt(nil, x), func(b []byte) (interface{}, int, error) { return ReadBigInt(b) }, x, ErrShortBuffer},
+		{AppendDecimal(nil, d), func(b []byte) (interface{}, int, error) { return ReadDecimal(b) }, d, ErrShortBuffer},
+		{inverted(AppendDecimal(nil, d)), func(b []byte) (interface{}, int, error) { return ReadDecimalDesc(b) }, d, ErrShortBuffer},
+		{AppendMemcomparableBytes(nil, bs), func(b []byte) (interface{}, int, error) { return ReadMemcomparableBytes(b) }, bs, ErrShortBuffer},
+		{AppendMemcomparableBytesDesc(nil, bs), func(b []byte) (interface{}, int, error) { return ReadMemcomparableBytesDesc(b) }, bs, ErrShortBuffer},
+		{AppendMemcomparableFloat64(nil, -4.2), func(b []byte) (interface{}, int, error) { return ReadMemcomparableFloat64(b) }, -4.2, ErrShortBuffer},
+	} {
+		//reading must not go beyond the value
+		v, n, err := c.read(append(c.b, 0xAB))
+		assert.Nil(t, err, "%d", i)
+		assert.Equal(t, len(c.b), n, "%d", i)
+		switch want := c.want.(type) {
+		case *big.Int:
+			assert.Equal(t, 0, want.Cmp(v.(*big.Int)), "%d", i)
+		case Decimal:
+			assert.Equal(t, want.String(), v.(Decimal).String(), "%d", i)
+		default:
+			assert.Equal(t, want, v, "%d", i)
+		}
+
+		for j := 0; j < len(c.b); j++ {
+			_, n, err := c.read(c.b[:j])
+			assert.Equal(t, c.short, err, "%d: %d bytes", i, j)
+			assert.Equal(t, 0, n, "%d: %d bytes", i, j)
+		}
+	}
+}
+
+func TestRead_invalid(t *testing.T) {
+	_, _, err := ReadEscapedString([]byte{'a', 0x00, 0x02})
+	assert.Equal(t, ErrInvalid, err)
+	_, _, err = ReadNumber([]byte{0xAB})
+	assert.Equal(t, ErrInvalid, err)
+	_, _, err = ReadDecimal([]byte{0xAB})
+	assert.Equal(t, ErrInvalid, err)
+	_, _, err = ReadDecimal([]byte{decPos, 0, 0, 0, 0, 0xFE})
+	assert.Equal(t, ErrInvalid, err)
+	_, _, err = ReadBigInt([]byte{0xAB})
+	assert.Equal(t, ErrInvalid, err)
+	_, _, err = ReadBigInt([]byte{bigPos, 0, 0, 0, 0})
+	assert.Equal(t, ErrInvalid, err)
+	_, _, err = ReadMemcomparableBytes([]byte{'a', 0, 0, 0, 0, 0, 0, 0, 0x00})
+	assert.Equal(t, ErrInvalid, err)
+	_, _, err = ReadMemcomparableBytes([]byte{'a', 'b', 0, 0, 0, 0, 0, 0, 0xF8})
+	assert.Equal(t, ErrInvalid, err)
+}
+
+func TestReadBigInt_length(t *testing.T) {
+	//a corrupt length must not be trusted to allocate or index
+	_, _, err := ReadBigInt([]byte{bigPos, 0xFF, 0xFF, 0xFF, 0xFF, 0x01})
+	assert.Equal(t, ErrShortBuffer, err)
+	_, _, err = ReadBigInt([]byte{bigNeg, 0x00, 0x00, 0x00, 0x00, 0xFE})
+	assert.Equal(t, ErrShortBuffer, err)
+}
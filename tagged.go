@@ -0,0 +1,185 @@
+package lex
+
+import (
+	"errors"
+	"reflect"
+	"time"
+)
+
+//Each component of a tagged key is preceded by a type code, chosen so that components of different types sort in a sensible order:
+//nil, then false and true, then numbers of any type, then byte slices, strings and times.
+const (
+	tagNil    = 0x00
+	tagFalse  = 0x01
+	tagTrue   = 0x02
+	tagNumber = 0x03
+	tagBytes  = 0x10
+	tagString = 0x11
+	tagTime   = 0x20
+)
+
+//taggedSize returns the number of bytes putTagged would generate to encode v, or -1 if v is not of a supported type.
+func taggedSize(v reflect.Value) int {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return 1
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Invalid, reflect.Bool:
+		return 1
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return 1 + NumberInt64Size(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return 1 + NumberUint64Size(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return 1 + NumberFloat64Size(v.Float())
+	case reflect.String:
+		return 1 + EscapedStringSize(v.String())
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return 1 + EscapedBytesSize(v.Bytes())
+		}
+	case reflect.Struct:
+		if v.Type() == timeType && timeInRange(v.Interface().(time.Time), time.Nanosecond) {
+			return 1 + TimeSize(time.Nanosecond)
+		}
+	}
+	return -1
+}
+
+func putTagged(b []byte, v reflect.Value) int {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			b[0] = tagNil
+			return 1
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Invalid:
+		b[0] = tagNil
+		return 1
+	case reflect.Bool:
+		b[0] = tagFalse
+		if v.Bool() {
+			b[0] = tagTrue
+		}
+		return 1
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		b[0] = tagNumber
+		return 1 + PutNumberInt64(b[1:], v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		b[0] = tagNumber
+		return 1 + PutNumberUint64(b[1:], v.Uint())
+	case reflect.Float32, reflect.Float64:
+		b[0] = tagNumber
+		return 1 + PutNumberFloat64(b[1:], v.Float())
+	case reflect.String:
+		s := v.String()
+		b[0] = tagString
+		PutEscapedString(b[1:], s)
+		return 1 + EscapedStringSize(s)
+	case reflect.Slice:
+		s := v.Bytes()
+		b[0] = tagBytes
+		PutEscapedBytes(b[1:], s)
+		return 1 + EscapedBytesSize(s)
+	default: //time.Time
+		b[0] = tagTime
+		PutTime(b[1:], v.Interface().(time.Time), time.Nanosecond)
+		return 1 + TimeSize(time.Nanosecond)
+	}
+}
+
+//TaggedKey creates an appropriately-sized slice and writes passed data to it, with each component preceded by a type code,
+//so that the key can be read back by ParseTaggedKey without knowledge of its layout.
+//Data must be nil, or of Boolean, Numeric, String, []byte or time.Time type, or a pointer to such data.
+//Numbers of any type are encoded as per PutNumberInt64, so that they can be compared with each other;
+//strings and byte slices are escaped, and times are encoded at nanosecond precision.
+func TaggedKey(data ...interface{}) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, errors.New("lex.TaggedKey: no data")
+	}
+
+	sum := 0
+	for _, d := range data {
+		n := taggedSize(reflect.ValueOf(d))
+		if n < 0 {
+			return nil, errors.New("lex.TaggedKey: invalid")
+		}
+		sum += n
+	}
+
+	b := make([]byte, sum)
+	offset := 0
+
+	for _, d := range data {
+		offset += putTagged(b[offset:], reflect.ValueOf(d))
+	}
+
+	return b, nil
+}
+
+//MustTaggedKey panics if TaggedKey(data...) returns a non-nil error.
+func MustTaggedKey(data ...interface{}) []byte {
+	b, err := TaggedKey(data...)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+//ParseTaggedKey reads a key created by TaggedKey, returning its components.
+//Components are returned as nil, bool, string, []byte or time.Time, with numbers returned as per ScanNumber.
+func ParseTaggedKey(b []byte) ([]interface{}, error) {
+	var data []interface{}
+	for len(b) > 0 {
+		v, n := scanTagged(b)
+		if n < 0 {
+			return nil, errors.New("lex.ParseTaggedKey: invalid")
+		}
+		data = append(data, v)
+		b = b[n:]
+	}
+	return data, nil
+}
+
+func scanTagged(b []byte) (interface{}, int) {
+	switch b[0] {
+	case tagNil:
+		return nil, 1
+	case tagFalse:
+		return false, 1
+	case tagTrue:
+		return true, 1
+	case tagNumber:
+		v, n := ScanNumber(b[1:])
+		if n < 0 {
+			return nil, -1
+		}
+		return v, 1 + n
+	case tagBytes:
+		v, n := ScanEscapedBytes(b[1:])
+		if n < 0 {
+			return nil, -1
+		}
+		return v, 1 + n
+	case tagString:
+		v, n := ScanEscapedString(b[1:])
+		if n < 0 {
+			return nil, -1
+		}
+		return v, 1 + n
+	case tagTime:
+		n := TimeSize(time.Nanosecond)
+		if len(b) < 1+n || !timeValid(b[1:], time.Nanosecond) {
+			return nil, -1
+		}
+		return Time(b[1:], time.Nanosecond), 1 + n
+	}
+	return nil, -1
+}
@@ -0,0 +1,89 @@
+package lex
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaggedKey(t *testing.T) {
+	when := time.Date(2017, 3, 14, 15, 9, 26, 535897932, time.UTC)
+	s := "howdy"
+
+	b, err := TaggedKey(nil, true, int8(-5), uint32(5), 2.5, "how\x00dy", []byte{0, 1}, when, &s, (*int)(nil))
+	assert.Nil(t, err)
+
+	actual, err := ParseTaggedKey(b)
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{nil, true, int64(-5), int64(5), 2.5, "how\x00dy", []byte{0, 1}, when, "howdy", nil}, actual)
+}
+
+func TestTaggedKey_order(t *testing.T) {
+	r := []interface{}{
+		nil,
+		false,
+		true,
+		math.Inf(-1),
+		int64(math.MinInt64),
+		-1.5,
+		int8(-1),
+		uint8(0),
+		0.5,
+		int16(1),
+		uint64(math.MaxUint64),
+		math.NaN(),
+		[]byte{},
+		[]byte{0},
+		"",
+		"a",
+		time.Unix(-1, 0),
+		time.Unix(0, 0),
+	}
+	var prev []byte
+	for _, v := range r {
+		b := MustTaggedKey(v, "suffix")
+		if prev != nil {
+			assert.Equal(t, -1, bytes.Compare(prev, b), "%v", v)
+		}
+		prev = b
+	}
+}
+
+func TestTaggedKey_invalid(t *testing.T) {
+	var tests = []interface{}{
+		struct{}{},
+		[]int{1},
+		map[string]int{},
+		1 + 2i,
+	}
+	for _, d := range tests {
+		_, err := TaggedKey(d)
+		assert.NotNil(t, err, "%T", d)
+	}
+
+	_, err := TaggedKey()
+	assert.NotNil(t, err)
+	assert.Panics(t, func() { MustTaggedKey(struct{}{}) })
+}
+
+func TestParseTaggedKey_badformat(t *testing.T) {
+	var tests = [][]byte{
+		{0xFF},
+		{tagNumber},
+		{tagString, 'a'},
+		{tagBytes, 0x00},
+		{tagTime, 0x80},
+		{tagTrue, tagNumber, numPos},
+	}
+	for i, b := range tests {
+		_, err := ParseTaggedKey(b)
+		assert.NotNil(t, err, "%v", i)
+	}
+
+	actual, err := ParseTaggedKey(nil)
+	assert.Nil(t, err)
+	assert.Empty(t, actual)
+}
@@ -0,0 +1,100 @@
+package lex
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+//unixToInternal is the number of seconds from the zero time.Time, in year 1, to the Unix epoch.
+const unixToInternal int64 = (1969*365 + 1969/4 - 1969/100 + 1969/400) * 24 * 60 * 60
+
+//maxUnix is the latest second since the Unix epoch that time.Unix can represent without overflow.
+const maxUnix = math.MaxInt64 - unixToInternal
+
+//TimeSize returns the number of bytes PutTime would generate to encode a time.Time with precision p.
+//Supported precisions are time.Nanosecond, time.Microsecond, time.Millisecond and time.Second.
+//If p is not supported, TimeSize returns -1.
+func TimeSize(p time.Duration) int {
+	switch p {
+	case time.Nanosecond:
+		return 12
+	case time.Microsecond, time.Millisecond, time.Second:
+		return 8
+	}
+	return -1
+}
+
+//PutTime serializes time.Time as TimeSize(p) bytes, truncated to precision p.
+//Order is preserved by encoding the instant in UTC, so the location is discarded.
+//
+//At nanosecond precision, the value is encoded as seconds since the Unix epoch followed by nanoseconds within the second, as per PutInt64 and PutUint32.
+//At other precisions, the value is encoded as a count of p since the Unix epoch, as per PutInt64.
+//Times before the epoch are rounded down, so that order is preserved.
+//
+//The count must fit in an int64, so at microsecond precision times are supported from about 290,000 BC to 294,000 AD,
+//and at millisecond precision within about 292 million years of 1970.
+//At nanosecond and second precision, all times with Unix seconds that time.Unix can represent are supported.
+//
+//PutTime panics if p is not a supported precision, or if v is outside the range supported at that precision.
+func PutTime(b []byte, v time.Time, p time.Duration) {
+	checkPrecision(p)
+	if !timeInRange(v, p) {
+		panic(errors.New("lex: time out of range for precision"))
+	}
+	if p == time.Nanosecond {
+		PutInt64(b, v.Unix())
+		PutUint32(b[8:12], uint32(v.Nanosecond()))
+		return
+	}
+	per := int64(time.Second / p)
+	PutInt64(b, v.Unix()*per+int64(v.Nanosecond())/int64(p))
+}
+
+//Time deserializes time.Time from TimeSize(p) bytes, encoded with precision p.
+//The value is returned in UTC.
+//
+//Time panics if p is not a supported precision.
+func Time(b []byte, p time.Duration) time.Time {
+	checkPrecision(p)
+	if p == time.Nanosecond {
+		return time.Unix(Int64(b), int64(Uint32(b[8:12]))).UTC()
+	}
+	return unixTime(Int64(b), p).UTC()
+}
+
+//unixTime returns the time n × p after the Unix epoch, with p a supported precision other than time.Nanosecond.
+func unixTime(n int64, p time.Duration) time.Time {
+	per := int64(time.Second / p)
+	sec, rem := n/per, n%per
+	if rem < 0 {
+		sec--
+		rem += per
+	}
+	return time.Unix(sec, rem*int64(p))
+}
+
+//timeInRange reports whether v can be encoded at precision p without overflow.
+//Comparing with Before and After, rather than Unix, remains correct for times whose Unix seconds would overflow.
+func timeInRange(v time.Time, p time.Duration) bool {
+	lo, hi := time.Unix(math.MinInt64, 0), time.Unix(maxUnix, int64(time.Second-1))
+	if p != time.Nanosecond && p != time.Second {
+		lo, hi = unixTime(math.MinInt64, p), unixTime(math.MaxInt64, p).Add(p-1)
+	}
+	return !v.Before(lo) && !v.After(hi)
+}
+
+//timeValid reports whether b, of length TimeSize(p), holds a time that PutTime could have written at precision p.
+func timeValid(b []byte, p time.Duration) bool {
+	if p == time.Nanosecond {
+		return Int64(b) <= maxUnix && Uint32(b[8:12]) < uint32(time.Second)
+	}
+	return Int64(b)/int64(time.Second/p) <= maxUnix
+}
+
+//checkPrecision panics if p is not a supported precision, before it can be used as a divisor.
+func checkPrecision(p time.Duration) {
+	if TimeSize(p) < 0 {
+		panic(errors.New("lex: invalid time precision"))
+	}
+}
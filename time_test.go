@@ -0,0 +1,172 @@
+package lex
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"testing/quick"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var precisions = []time.Duration{time.Nanosecond, time.Microsecond, time.Millisecond, time.Second}
+
+func TestTime(t *testing.T) {
+	r := []time.Time{
+		time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(1066, 10, 14, 9, 0, 0, 0, time.UTC),
+		time.Date(1969, 12, 31, 23, 59, 58, 999999999, time.UTC),
+		time.Date(1969, 12, 31, 23, 59, 59, 0, time.UTC),
+		time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(1970, 1, 1, 0, 0, 1, 0, time.UTC),
+		time.Date(2038, 1, 19, 3, 14, 8, 0, time.UTC),
+		time.Date(9999, 12, 31, 23, 59, 59, 999999999, time.UTC),
+	}
+	for _, p := range precisions {
+		var prev []byte
+		for _, v := range r {
+			b := make([]byte, TimeSize(p))
+			PutTime(b, v, p)
+
+			v1 := Time(b, p)
+			assert.Equal(t, v.Truncate(p), v1, "%v", p)
+
+			if prev != nil {
+				assert.Equal(t, -1, bytes.Compare(prev, b), "%v %v", p, v)
+			}
+			prev = b
+		}
+	}
+}
+
+func TestTime_precision(t *testing.T) {
+	v := time.Date(1969, 12, 31, 23, 59, 59, 123456789, time.UTC)
+	var tests = []struct {
+		p        time.Duration
+		expected time.Time
+	}{
+		{time.Nanosecond, time.Date(1969, 12, 31, 23, 59, 59, 123456789, time.UTC)},
+		{time.Microsecond, time.Date(1969, 12, 31, 23, 59, 59, 123456000, time.UTC)},
+		{time.Millisecond, time.Date(1969, 12, 31, 23, 59, 59, 123000000, time.UTC)},
+		{time.Second, time.Date(1969, 12, 31, 23, 59, 59, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		b := make([]byte, TimeSize(tt.p))
+		PutTime(b, v, tt.p)
+		assert.Equal(t, tt.expected, Time(b, tt.p))
+	}
+
+	b := make([]byte, 8)
+	PutTime(b, v, time.Second)
+	assert.Equal(t, int64(-1), Int64(b))
+}
+
+func TestTime_location(t *testing.T) {
+	loc := time.FixedZone("UTC-5", -5*60*60)
+	v1 := time.Date(2000, 1, 1, 0, 0, 0, 0, loc)
+	v2 := time.Date(2000, 1, 1, 4, 0, 0, 0, time.UTC)
+
+	b1 := make([]byte, 12)
+	PutTime(b1, v1, time.Nanosecond)
+
+	b2 := make([]byte, 12)
+	PutTime(b2, v2, time.Nanosecond)
+
+	assert.Equal(t, 1, bytes.Compare(b1, b2)) //v1 is 05:00 UTC
+	assert.Equal(t, time.UTC, Time(b1, time.Nanosecond).Location())
+	assert.True(t, v1.Equal(Time(b1, time.Nanosecond)))
+}
+
+func TestTime_RandomCompare(t *testing.T) {
+	f := func(s1, s2 int64, n1, n2 uint32) bool {
+		a1 := time.Unix(s1%math.MaxInt32, int64(n1%1e9))
+		a2 := time.Unix(s2%math.MaxInt32, int64(n2%1e9))
+
+		b1 := make([]byte, 12)
+		PutTime(b1, a1, time.Nanosecond)
+
+		b2 := make([]byte, 12)
+		PutTime(b2, a2, time.Nanosecond)
+
+		var expected int
+		switch {
+		case a1.Before(a2):
+			expected = -1
+		case a1.After(a2):
+			expected = +1
+		}
+		return bytes.Compare(b1, b2) == expected && Time(b1, time.Nanosecond).Equal(a1)
+	}
+	assert.Nil(t, quick.Check(f, nil))
+}
+
+func TestTime_invalid(t *testing.T) {
+	assert.Equal(t, -1, TimeSize(0))
+	assert.Equal(t, -1, TimeSize(time.Minute))
+	assert.Equal(t, -1, TimeSize(3*time.Millisecond))
+
+	b := make([]byte, 12)
+	assert.PanicsWithError(t, "lex: invalid time precision", func() { PutTime(b, time.Now(), time.Hour) })
+	assert.PanicsWithError(t, "lex: invalid time precision", func() { Time(b, 0) })
+	assert.PanicsWithError(t, "lex: invalid time precision", func() { AppendTime(nil, time.Now(), 0) })
+	assert.PanicsWithError(t, "lex: invalid time precision", func() { ReadTime(b, 7) })
+}
+
+func TestTime_range(t *testing.T) {
+	for _, p := range []time.Duration{time.Microsecond, time.Millisecond} {
+		b := make([]byte, 8)
+		lo, hi := unixTime(math.MinInt64, p), unixTime(math.MaxInt64, p).Add(p-1)
+
+		PutTime(b, lo, p)
+		assert.Equal(t, int64(math.MinInt64), Int64(b), "%v", p)
+		assert.True(t, lo.Equal(Time(b, p)), "%v", p)
+		PutTime(b, hi, p)
+		assert.Equal(t, int64(math.MaxInt64), Int64(b), "%v", p)
+		assert.True(t, hi.Truncate(p).Equal(Time(b, p)), "%v", p)
+
+		assert.PanicsWithError(t, "lex: time out of range for precision", func() { PutTime(b, lo.Add(-1), p) }, "%v", p)
+		assert.PanicsWithError(t, "lex: time out of range for precision", func() { PutTime(b, hi.Add(1), p) }, "%v", p)
+	}
+
+	for _, p := range []time.Duration{time.Nanosecond, time.Second} {
+		b := make([]byte, TimeSize(p))
+		lo, hi := time.Unix(math.MinInt64, 0), time.Unix(maxUnix, 999999999)
+
+		PutTime(b, lo, p)
+		assert.True(t, lo.Equal(Time(b, p)), "%v", p)
+		PutTime(b, hi, p)
+		assert.True(t, hi.Truncate(p).Equal(Time(b, p)), "%v", p)
+
+		//earlier times have Unix seconds that do not fit in an int64
+		assert.PanicsWithError(t, "lex: time out of range for precision", func() { PutTime(b, lo.Add(-time.Second), p) }, "%v", p)
+	}
+
+	//a key cannot hold such times, rather than panicking
+	assert.Equal(t, -1, Size(time.Unix(math.MinInt64, 0).Add(-time.Second)))
+}
+
+func TestReadTime_invalid(t *testing.T) {
+	b := make([]byte, 12)
+	PutInt64(b, 1)
+	PutUint32(b[8:], 1e9) //nanoseconds must be within the second
+	_, _, err := ReadTime(b, time.Nanosecond)
+	assert.Equal(t, ErrInvalid, err)
+
+	PutInt64(b, math.MaxInt64) //beyond the Unix seconds time.Unix can represent
+	PutUint32(b[8:], 0)
+	_, _, err = ReadTime(b, time.Nanosecond)
+	assert.Equal(t, ErrInvalid, err)
+	_, _, err = ReadTime(b, time.Second)
+	assert.Equal(t, ErrInvalid, err)
+
+	_, _, err = ReadTime(b, time.Millisecond)
+	assert.Nil(t, err)
+}
+
+func TestTime_ZeroAllocs(t *testing.T) {
+	v := time.Now()
+	b := make([]byte, 12)
+	assert.Zero(t, testing.AllocsPerRun(1, func() { PutTime(b, v, time.Nanosecond) }))
+	assert.Zero(t, testing.AllocsPerRun(1, func() { Time(b, time.Nanosecond) }))
+}
@@ -0,0 +1,527 @@
+//Package tuple implements the FoundationDB tuple layer encoding, using lex primitives where the formats coincide.
+//
+//Keys are packed and unpacked byte-for-byte compatibly with the FoundationDB client bindings, so that keys written by other languages can be read in Go, and vice versa, without depending on the FoundationDB client.
+//
+//Supported elements are nil, []byte, string, nested tuples, integers of any width including *big.Int, float32, float64, bool, UUID and Versionstamp.
+package tuple
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"math/bits"
+
+	"github.com/xcdb/lex"
+)
+
+//Tuple is an ordered sequence of elements, which may itself be nested within a tuple.
+type Tuple []interface{}
+
+//UUID is a 128-bit universally unique identifier, encoded as its 16 bytes.
+type UUID [16]byte
+
+//Versionstamp is a 96-bit value assigned by the database at commit time,
+//comprising the 10 byte transaction version followed by a 2 byte user version.
+type Versionstamp struct {
+	TransactionVersion [10]byte
+	UserVersion        uint16
+}
+
+//Type codes, as per the FoundationDB tuple layer specification.
+const (
+	nilCode          = 0x00
+	bytesCode        = 0x01
+	stringCode       = 0x02
+	nestedCode       = 0x05
+	negBigCode       = 0x0B
+	intZeroCode      = 0x14
+	posBigCode       = 0x1D
+	floatCode        = 0x20
+	doubleCode       = 0x21
+	falseCode        = 0x26
+	trueCode         = 0x27
+	uuidCode         = 0x30
+	versionstampCode = 0x33
+)
+
+//Within byte strings and nested tuples, a NUL is escaped by following it with escapedNul.
+const escapedNul = 0xFF
+
+//Pack encodes data as a tuple, in the FoundationDB tuple layer format.
+func Pack(data ...interface{}) ([]byte, error) {
+	sum := 0
+	for _, d := range data {
+		n := size(d, false)
+		if n < 0 {
+			return nil, errors.New("tuple.Pack: invalid")
+		}
+		sum += n
+	}
+
+	b := make([]byte, sum)
+	offset := 0
+
+	for _, d := range data {
+		offset += put(b[offset:], d, false)
+	}
+
+	return b, nil
+}
+
+//MustPack panics if Pack(data...) returns a non-nil error.
+func MustPack(data ...interface{}) []byte {
+	b, err := Pack(data...)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+//Pack encodes t, as per Pack(t...).
+func (t Tuple) Pack() ([]byte, error) {
+	return Pack(t...)
+}
+
+//Unpack decodes a tuple from b, which must contain a single packed tuple with nothing following it.
+//
+//Integers are returned as int64, or as uint64 if they exceed math.MaxInt64, or as *big.Int if they fit neither.
+//Byte strings are returned as []byte, strings as string, and nested tuples as Tuple.
+func Unpack(b []byte) (Tuple, error) {
+	t := Tuple{}
+	for len(b) > 0 {
+		v, n := scan(b, false)
+		if n < 0 {
+			return nil, errors.New("tuple.Unpack: invalid")
+		}
+		t = append(t, v)
+		b = b[n:]
+	}
+	return t, nil
+}
+
+//
+
+func escapedSize(b []byte) int {
+	n := len(b) + 2
+	for _, c := range b {
+		if c == 0x00 {
+			n++
+		}
+	}
+	return n
+}
+
+func putEscaped(b []byte, code byte, v []byte) int {
+	b[0] = code
+	n := 1
+	for _, c := range v {
+		b[n] = c
+		n++
+		if c == 0x00 {
+			b[n] = escapedNul
+			n++
+		}
+	}
+	b[n] = 0x00
+	return n + 1
+}
+
+//scanEscaped returns the unescaped bytes following the type code, and the number of bytes read including the type code.
+func scanEscaped(b []byte) ([]byte, int) {
+	v := make([]byte, 0, len(b))
+	for i := 1; i < len(b); i++ {
+		if b[i] != 0x00 {
+			v = append(v, b[i])
+			continue
+		}
+		if i+1 < len(b) && b[i+1] == escapedNul {
+			v = append(v, 0x00)
+			i++
+			continue
+		}
+		return v, i + 1
+	}
+	return nil, -1
+}
+
+//
+
+func uintSize(v uint64) int {
+	return 1 + (bits.Len64(v)+7)/8
+}
+
+//putUint writes the type code and magnitude of an integer of up to 8 bytes.
+//Negative values store the ones' complement of the magnitude, with a type code below intZeroCode.
+func putUint(b []byte, neg bool, v uint64) int {
+	n := (bits.Len64(v) + 7) / 8
+	var t [8]byte
+	lex.PutUint64(t[:], v)
+	copy(b[1:], t[8-n:])
+	if neg {
+		b[0] = byte(intZeroCode - n)
+		invert(b[1 : 1+n])
+	} else {
+		b[0] = byte(intZeroCode + n)
+	}
+	return 1 + n
+}
+
+func intSize(v int64) int {
+	if v < 0 {
+		return uintSize(uint64(-v)) //-MinInt64 wraps to 1<<63
+	}
+	return uintSize(uint64(v))
+}
+
+func putInt(b []byte, v int64) int {
+	if v < 0 {
+		return putUint(b, true, uint64(-v))
+	}
+	return putUint(b, false, uint64(v))
+}
+
+func bigSize(v *big.Int) int {
+	n := (v.BitLen() + 7) / 8
+	switch {
+	case n <= 8:
+		return 1 + n
+	case n <= math.MaxUint8:
+		return 2 + n
+	}
+	return -1
+}
+
+func putBig(b []byte, v *big.Int) int {
+	n := (v.BitLen() + 7) / 8
+	if n <= 8 {
+		var m big.Int
+		return putUint(b, v.Sign() < 0, m.Abs(v).Uint64())
+	}
+	v.FillBytes(b[2 : 2+n])
+	if v.Sign() < 0 {
+		b[0] = negBigCode
+		b[1] = byte(n) ^ 0xFF
+		invert(b[2 : 2+n])
+	} else {
+		b[0] = posBigCode
+		b[1] = byte(n)
+	}
+	return 2 + n
+}
+
+func scanInt(b []byte) (interface{}, int) {
+	code := int(b[0])
+	if code == negBigCode || code == posBigCode {
+		if len(b) < 2 {
+			return nil, -1
+		}
+		neg := code == negBigCode
+		n := int(b[1])
+		if neg {
+			n ^= 0xFF
+		}
+		if len(b) < 2+n {
+			return nil, -1
+		}
+		m := make([]byte, n)
+		copy(m, b[2:2+n])
+		if neg {
+			invert(m)
+		}
+		v := new(big.Int).SetBytes(m)
+		if neg {
+			v.Neg(v)
+		}
+		return v, 2 + n
+	}
+
+	neg := code < intZeroCode
+	n := code - intZeroCode
+	if neg {
+		n = -n
+	}
+	if len(b) < 1+n {
+		return nil, -1
+	}
+	var t [8]byte
+	copy(t[8-n:], b[1:1+n])
+	if neg {
+		invert(t[8-n:])
+	}
+	v := lex.Uint64(t[:])
+
+	switch {
+	case !neg && v <= math.MaxInt64:
+		return int64(v), 1 + n
+	case !neg:
+		return v, 1 + n
+	case v <= 1<<63:
+		return -int64(v), 1 + n
+	}
+	return new(big.Int).Neg(new(big.Int).SetUint64(v)), 1 + n
+}
+
+//
+
+//Floats are encoded in big-endian, with the sign bit flipped for positive values and all bits inverted for negative values.
+func putFloat(b []byte, v float32) {
+	u := math.Float32bits(v)
+	if u&(1<<31) != 0 {
+		u = ^u
+	} else {
+		u ^= 1 << 31
+	}
+	lex.PutUint32(b, u)
+}
+
+func float(b []byte) float32 {
+	u := lex.Uint32(b)
+	if u&(1<<31) == 0 {
+		u = ^u
+	} else {
+		u ^= 1 << 31
+	}
+	return math.Float32frombits(u)
+}
+
+func putDouble(b []byte, v float64) {
+	u := math.Float64bits(v)
+	if u&(1<<63) != 0 {
+		u = ^u
+	} else {
+		u ^= 1 << 63
+	}
+	lex.PutUint64(b, u)
+}
+
+func double(b []byte) float64 {
+	u := lex.Uint64(b)
+	if u&(1<<63) == 0 {
+		u = ^u
+	} else {
+		u ^= 1 << 63
+	}
+	return math.Float64frombits(u)
+}
+
+func invert(b []byte) {
+	for i := range b {
+		b[i] = ^b[i]
+	}
+}
+
+//
+
+//size returns the number of bytes put would generate to encode v, or -1 if v is not of a supported type.
+//Within a nested tuple, nil is escaped so that it is not mistaken for the terminator.
+func size(v interface{}, nested bool) int {
+	switch v := v.(type) {
+	case nil:
+		if nested {
+			return 2
+		}
+		return 1
+	case bool:
+		return 1
+	case []byte:
+		return escapedSize(v)
+	case string:
+		return escapedSize([]byte(v))
+	case Tuple:
+		return nestedSize(v)
+	case []interface{}:
+		return nestedSize(v)
+	case int:
+		return intSize(int64(v))
+	case int8:
+		return intSize(int64(v))
+	case int16:
+		return intSize(int64(v))
+	case int32:
+		return intSize(int64(v))
+	case int64:
+		return intSize(v)
+	case uint:
+		return uintSize(uint64(v))
+	case uint8:
+		return uintSize(uint64(v))
+	case uint16:
+		return uintSize(uint64(v))
+	case uint32:
+		return uintSize(uint64(v))
+	case uint64:
+		return uintSize(v)
+	case *big.Int:
+		return bigSize(v)
+	case big.Int:
+		return bigSize(&v)
+	case float32:
+		return 5
+	case float64:
+		return 9
+	case UUID:
+		return 17
+	case Versionstamp:
+		return 13
+	}
+	return -1
+}
+
+func nestedSize(t []interface{}) int {
+	sum := 2
+	for _, d := range t {
+		n := size(d, true)
+		if n < 0 {
+			return -1
+		}
+		sum += n
+	}
+	return sum
+}
+
+func put(b []byte, v interface{}, nested bool) int {
+	switch v := v.(type) {
+	case nil:
+		b[0] = nilCode
+		if nested {
+			b[1] = escapedNul
+			return 2
+		}
+		return 1
+	case bool:
+		b[0] = falseCode
+		if v {
+			b[0] = trueCode
+		}
+		return 1
+	case []byte:
+		return putEscaped(b, bytesCode, v)
+	case string:
+		return putEscaped(b, stringCode, []byte(v))
+	case Tuple:
+		return putNested(b, v)
+	case []interface{}:
+		return putNested(b, v)
+	case int:
+		return putInt(b, int64(v))
+	case int8:
+		return putInt(b, int64(v))
+	case int16:
+		return putInt(b, int64(v))
+	case int32:
+		return putInt(b, int64(v))
+	case int64:
+		return putInt(b, v)
+	case uint:
+		return putUint(b, false, uint64(v))
+	case uint8:
+		return putUint(b, false, uint64(v))
+	case uint16:
+		return putUint(b, false, uint64(v))
+	case uint32:
+		return putUint(b, false, uint64(v))
+	case uint64:
+		return putUint(b, false, v)
+	case *big.Int:
+		return putBig(b, v)
+	case big.Int:
+		return putBig(b, &v)
+	case float32:
+		b[0] = floatCode
+		putFloat(b[1:5], v)
+		return 5
+	case float64:
+		b[0] = doubleCode
+		putDouble(b[1:9], v)
+		return 9
+	case UUID:
+		b[0] = uuidCode
+		copy(b[1:17], v[:])
+		return 17
+	case Versionstamp:
+		b[0] = versionstampCode
+		copy(b[1:11], v.TransactionVersion[:])
+		lex.PutUint16(b[11:13], v.UserVersion)
+		return 13
+	}
+	return 0
+}
+
+func putNested(b []byte, t []interface{}) int {
+	b[0] = nestedCode
+	n := 1
+	for _, d := range t {
+		n += put(b[n:], d, true)
+	}
+	b[n] = 0x00
+	return n + 1
+}
+
+//scan decodes a single element from b, returning the value and the number of bytes read.
+//If b does not begin with a valid element, scan returns nil and -1.
+func scan(b []byte, nested bool) (interface{}, int) {
+	switch code := b[0]; {
+	case code == nilCode:
+		if nested {
+			if len(b) < 2 || b[1] != escapedNul {
+				return nil, -1
+			}
+			return nil, 2
+		}
+		return nil, 1
+	case code == bytesCode:
+		return scanEscaped(b)
+	case code == stringCode:
+		v, n := scanEscaped(b)
+		if n < 0 {
+			return nil, -1
+		}
+		return string(v), n
+	case code == nestedCode:
+		t := Tuple{}
+		for i := 1; i < len(b); {
+			if b[i] == 0x00 && (i+1 >= len(b) || b[i+1] != escapedNul) {
+				return t, i + 1
+			}
+			v, n := scan(b[i:], true)
+			if n < 0 {
+				return nil, -1
+			}
+			t = append(t, v)
+			i += n
+		}
+		return nil, -1
+	case code >= negBigCode && code <= posBigCode:
+		return scanInt(b)
+	case code == floatCode:
+		if len(b) < 5 {
+			return nil, -1
+		}
+		return float(b[1:5]), 5
+	case code == doubleCode:
+		if len(b) < 9 {
+			return nil, -1
+		}
+		return double(b[1:9]), 9
+	case code == falseCode:
+		return false, 1
+	case code == trueCode:
+		return true, 1
+	case code == uuidCode:
+		if len(b) < 17 {
+			return nil, -1
+		}
+		var v UUID
+		copy(v[:], b[1:17])
+		return v, 17
+	case code == versionstampCode:
+		if len(b) < 13 {
+			return nil, -1
+		}
+		var v Versionstamp
+		copy(v.TransactionVersion[:], b[1:11])
+		v.UserVersion = lex.Uint16(b[11:13])
+		return v, 13
+	}
+	return nil, -1
+}
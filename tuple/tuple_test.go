@@ -0,0 +1,160 @@
+package tuple
+
+import (
+	"bytes"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func bigint(s string) *big.Int {
+	v, _ := new(big.Int).SetString(s, 0)
+	return v
+}
+
+func TestPack(t *testing.T) {
+	//expected encodings as per the FoundationDB tuple layer specification and bindings
+	var tests = []struct {
+		v        interface{}
+		expected []byte
+	}{
+		{nil, []byte{0x00}},
+		{[]byte("foo\x00bar"), []byte{0x01, 'f', 'o', 'o', 0x00, 0xFF, 'b', 'a', 'r', 0x00}},
+		{"FÔO\x00bar", []byte{0x02, 'F', 0xC3, 0x94, 'O', 0x00, 0xFF, 'b', 'a', 'r', 0x00}},
+		{Tuple{[]byte("foo\x00bar"), nil, Tuple{}}, []byte{0x05, 0x01, 'f', 'o', 'o', 0x00, 0xFF, 'b', 'a', 'r', 0x00, 0x00, 0xFF, 0x05, 0x00, 0x00}},
+		{-5551212, []byte{0x11, 0xAB, 0x4B, 0x93}},
+		{0, []byte{0x14}},
+		{1, []byte{0x15, 0x01}},
+		{-1, []byte{0x13, 0xFE}},
+		{255, []byte{0x15, 0xFF}},
+		{256, []byte{0x16, 0x01, 0x00}},
+		{int64(math.MinInt64), []byte{0x0C, 0x7F, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}},
+		{uint64(math.MaxUint64), []byte{0x1C, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}},
+		{bigint("0x10000000000000000"), []byte{0x1D, 0x09, 0x01, 0, 0, 0, 0, 0, 0, 0, 0}},
+		{bigint("-0x10000000000000000"), []byte{0x0B, 0xF6, 0xFE, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}},
+		{float32(42), []byte{0x20, 0xC2, 0x28, 0x00, 0x00}},
+		{float32(-42), []byte{0x20, 0x3D, 0xD7, 0xFF, 0xFF}},
+		{-42.0, []byte{0x21, 0x3F, 0xBA, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}},
+		{false, []byte{0x26}},
+		{true, []byte{0x27}},
+		{UUID{0: 0x12, 15: 0x34}, []byte{0x30, 0x12, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0x34}},
+		{Versionstamp{[10]byte{9: 0x01}, 2}, []byte{0x33, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0x01, 0x00, 0x02}},
+	}
+	for _, tt := range tests {
+		actual, err := Pack(tt.v)
+		assert.Nil(t, err)
+		assert.Equal(t, tt.expected, actual, "%v", tt.v)
+	}
+}
+
+func TestUnpack(t *testing.T) {
+	expected := Tuple{
+		nil,
+		[]byte{0, 1},
+		"howdy",
+		Tuple{nil, "nested", Tuple{int64(1)}},
+		int64(-42),
+		int64(math.MinInt64),
+		uint64(math.MaxUint64),
+		bigint("-0xFFFFFFFFFFFFFFFF"),
+		bigint("0x10000000000000000"),
+		float32(1.5),
+		math.Inf(-1),
+		false,
+		true,
+		UUID{1, 2, 3},
+		Versionstamp{[10]byte{1, 2, 3}, 42},
+	}
+
+	b, err := expected.Pack()
+	assert.Nil(t, err)
+
+	actual, err := Unpack(b)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestPack_order(t *testing.T) {
+	r := []interface{}{
+		nil,
+		[]byte{},
+		[]byte{0},
+		[]byte{0, 0},
+		"",
+		"a",
+		Tuple{},
+		Tuple{nil},
+		Tuple{nil, nil},
+		Tuple{0},
+		bigint("-0x10000000000000000"),
+		bigint("-0xFFFFFFFFFFFFFFFF"),
+		math.MinInt64,
+		-256,
+		-255,
+		-1,
+		0,
+		1,
+		255,
+		256,
+		math.MaxInt64,
+		uint64(math.MaxUint64),
+		bigint("0x10000000000000000"),
+		float32(math.Inf(-1)),
+		float32(-1),
+		float32(0),
+		float32(1),
+		math.Inf(-1),
+		-1.0,
+		0.0,
+		1.0,
+		false,
+		true,
+		UUID{},
+		UUID{0xFF},
+		Versionstamp{},
+	}
+	var prev []byte
+	for _, v := range r {
+		b := MustPack(v, "suffix")
+		if prev != nil {
+			assert.Equal(t, -1, bytes.Compare(prev, b), "%v", v)
+		}
+		prev = b
+	}
+}
+
+func TestPack_invalid(t *testing.T) {
+	var tests = []interface{}{
+		struct{}{},
+		[]int{1},
+		Tuple{1 + 2i},
+		new(big.Int).Lsh(big.NewInt(1), 8*256),
+	}
+	for _, d := range tests {
+		_, err := Pack(d)
+		assert.NotNil(t, err, "%T", d)
+	}
+	assert.Panics(t, func() { MustPack(struct{}{}) })
+}
+
+func TestUnpack_badformat(t *testing.T) {
+	var tests = [][]byte{
+		{0xFF},
+		{bytesCode, 'a'},
+		{stringCode, 'a', 0x00, 0xFF},
+		{nestedCode, 0x00, 0x01},
+		{nestedCode, 0x15},
+		{0x16, 0x01},
+		{posBigCode, 0x09, 0x01},
+		{floatCode, 0x00},
+		{doubleCode, 0x00},
+		{uuidCode, 0x00},
+		{versionstampCode, 0x00},
+	}
+	for i, b := range tests {
+		_, err := Unpack(b)
+		assert.NotNil(t, err, "%v", i)
+	}
+}
@@ -0,0 +1,143 @@
+package lex
+
+import (
+	"math/bits"
+)
+
+//Variable-length integers are encoded as a single byte when small, and otherwise as a length
+//marker followed by the big-endian value. Markers are chosen so that longer encodings of larger
+//magnitudes sort after (or, for negative values, before) shorter ones.
+const (
+	uvarintMax = 0xF7 //largest single byte unsigned value
+	varintNeg  = 0x08 //marker for negative values is varintNeg - length
+	varintMin  = -120 //smallest single byte signed value
+	varintMax  = 119  //largest single byte signed value
+	varintZero = 0x80 //single byte signed values are offset by varintZero
+	varintPos  = 0xF7 //marker for positive or large unsigned values is varintPos + length
+)
+
+func byteLen(v uint64) int {
+	return (bits.Len64(v) + 7) / 8
+}
+
+//UvarintSize returns the number of bytes PutUvarint would generate to encode v.
+func UvarintSize(v uint64) int {
+	if v <= uvarintMax {
+		return 1
+	}
+	return 1 + byteLen(v)
+}
+
+//PutUvarint serializes uint64 as between 1 and 9 bytes, returning the number of bytes written.
+//Values up to 247 are encoded as a single byte.
+//Larger values are encoded as a marker byte, from 0xF8 to 0xFF, indicating the number of bytes that follow,
+//and then the value in big-endian, so that order is preserved.
+func PutUvarint(b []byte, v uint64) int {
+	if v <= uvarintMax {
+		b[0] = byte(v)
+		return 1
+	}
+	n := byteLen(v)
+	b[0] = byte(varintPos + n)
+	putUintN(b[1:1+n], v)
+	return 1 + n
+}
+
+//ScanUvarint deserializes uint64 from byte slice, returning the value and the number of bytes read.
+//Assumes that other values may be stored after the encoded value.
+//If b is too short, ScanUvarint returns 0 and -1.
+func ScanUvarint(b []byte) (uint64, int) {
+	if len(b) == 0 {
+		return 0, -1
+	}
+	if b[0] <= uvarintMax {
+		return uint64(b[0]), 1
+	}
+	n := int(b[0]) - varintPos
+	if len(b) < 1+n {
+		return 0, -1
+	}
+	return uintN(b[1 : 1+n]), 1 + n
+}
+
+//VarintSize returns the number of bytes PutVarint would generate to encode v.
+func VarintSize(v int64) int {
+	switch {
+	case v >= varintMin && v <= varintMax:
+		return 1
+	case v > 0:
+		return 1 + byteLen(uint64(v))
+	default:
+		return 1 + byteLen(uint64(^v))
+	}
+}
+
+//PutVarint serializes int64 as between 1 and 9 bytes, returning the number of bytes written.
+//Values from -120 to 119 are encoded as a single byte.
+//Larger positive values are encoded as a marker byte, from 0xF8 to 0xFF, indicating the number of bytes that follow,
+//and then the value in big-endian.
+//Smaller negative values are encoded as a marker byte, from 0x07 down to 0x00, indicating the number of bytes that follow,
+//and then the value in big-endian two's complement, so that order is preserved.
+func PutVarint(b []byte, v int64) int {
+	switch {
+	case v >= varintMin && v <= varintMax:
+		b[0] = byte(v + varintZero)
+		return 1
+	case v > 0:
+		n := byteLen(uint64(v))
+		b[0] = byte(varintPos + n)
+		putUintN(b[1:1+n], uint64(v))
+		return 1 + n
+	default:
+		n := byteLen(uint64(^v))
+		b[0] = byte(varintNeg - n)
+		putUintN(b[1:1+n], uint64(v))
+		return 1 + n
+	}
+}
+
+//ScanVarint deserializes int64 from byte slice, returning the value and the number of bytes read.
+//Assumes that other values may be stored after the encoded value.
+//If b is too short, ScanVarint returns 0 and -1.
+func ScanVarint(b []byte) (int64, int) {
+	if len(b) == 0 {
+		return 0, -1
+	}
+	switch m := int(b[0]); {
+	case m >= varintNeg && m <= varintPos:
+		return int64(m - varintZero), 1
+	case m > varintPos:
+		n := m - varintPos
+		if len(b) < 1+n {
+			return 0, -1
+		}
+		return int64(uintN(b[1 : 1+n])), 1 + n
+	default:
+		n := varintNeg - m
+		if len(b) < 1+n {
+			return 0, -1
+		}
+		v := uintN(b[1 : 1+n])
+		if n < 8 {
+			v |= ^uint64(0) << (8 * uint(n))
+		}
+		return int64(v), 1 + n
+	}
+}
+
+//putUintN writes the low len(b) bytes of v into b in big-endian.
+func putUintN(b []byte, v uint64) {
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i] = byte(v)
+		v >>= 8
+	}
+}
+
+//uintN reads a big-endian value of len(b) bytes.
+func uintN(b []byte) uint64 {
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return v
+}
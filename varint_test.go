@@ -0,0 +1,143 @@
+package lex
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"testing/quick"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUvarint(t *testing.T) {
+	r := []uint64{0, 1, 42, 247, 248, 255, 256, 65535, 65536, math.MaxUint32, math.MaxUint32 + 1, math.MaxUint64}
+	var prev []byte
+	for _, v := range r {
+		b := make([]byte, UvarintSize(v))
+		n := PutUvarint(b, v)
+		assert.Equal(t, len(b), n)
+
+		v1, n1 := ScanUvarint(b)
+		assert.Equal(t, v, v1)
+		assert.Equal(t, n, n1)
+
+		if prev != nil {
+			assert.Equal(t, -1, bytes.Compare(prev, b), "%v", v)
+		}
+		prev = b
+	}
+}
+
+func TestUvarint_size(t *testing.T) {
+	var tests = []struct {
+		v    uint64
+		size int
+	}{
+		{0, 1}, {247, 1}, {248, 2}, {255, 2}, {256, 3}, {65535, 3}, {65536, 4}, {math.MaxUint64, 9},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.size, UvarintSize(tt.v), "%v", tt.v)
+	}
+}
+
+func TestUvarint_RandomCompare(t *testing.T) {
+	f := func(a1, a2 uint64, s1, s2 uint8) bool {
+		//shift to cover all lengths
+		a1 >>= s1 % 64
+		a2 >>= s2 % 64
+
+		b1 := make([]byte, 9)
+		b1 = b1[:PutUvarint(b1, a1)]
+
+		b2 := make([]byte, 9)
+		b2 = b2[:PutUvarint(b2, a2)]
+
+		var expected int
+		switch {
+		case a1 < a2:
+			expected = -1
+		case a1 > a2:
+			expected = +1
+		}
+		v1, _ := ScanUvarint(b1)
+		return bytes.Compare(b1, b2) == expected && v1 == a1
+	}
+	assert.Nil(t, quick.Check(f, nil))
+}
+
+func TestVarint(t *testing.T) {
+	r := []int64{math.MinInt64, math.MinInt32 - 1, math.MinInt32, -65537, -65536, -257, -256, -121, -120, -1, 0, 1, 119, 120, 255, 256, math.MaxInt32, math.MaxInt64}
+	var prev []byte
+	for _, v := range r {
+		b := make([]byte, VarintSize(v))
+		n := PutVarint(b, v)
+		assert.Equal(t, len(b), n)
+
+		v1, n1 := ScanVarint(b)
+		assert.Equal(t, v, v1)
+		assert.Equal(t, n, n1)
+
+		if prev != nil {
+			assert.Equal(t, -1, bytes.Compare(prev, b), "%v", v)
+		}
+		prev = b
+	}
+}
+
+func TestVarint_size(t *testing.T) {
+	var tests = []struct {
+		v    int64
+		size int
+	}{
+		{0, 1}, {-120, 1}, {119, 1}, {-121, 2}, {120, 2}, {-256, 2}, {255, 2}, {-257, 3}, {256, 3}, {math.MinInt64, 9}, {math.MaxInt64, 9},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.size, VarintSize(tt.v), "%v", tt.v)
+	}
+}
+
+func TestVarint_RandomCompare(t *testing.T) {
+	f := func(a1, a2 int64, s1, s2 uint8) bool {
+		//shift to cover all lengths
+		a1 >>= s1 % 64
+		a2 >>= s2 % 64
+
+		b1 := make([]byte, 9)
+		b1 = b1[:PutVarint(b1, a1)]
+
+		b2 := make([]byte, 9)
+		b2 = b2[:PutVarint(b2, a2)]
+
+		var expected int
+		switch {
+		case a1 < a2:
+			expected = -1
+		case a1 > a2:
+			expected = +1
+		}
+		v1, _ := ScanVarint(b1)
+		return bytes.Compare(b1, b2) == expected && v1 == a1
+	}
+	assert.Nil(t, quick.Check(f, nil))
+}
+
+func TestVarint_badformat(t *testing.T) {
+	_, n := ScanVarint(nil)
+	assert.Equal(t, -1, n)
+	_, n = ScanVarint([]byte{0xF9, 0x01}) //2 byte value, truncated
+	assert.Equal(t, -1, n)
+	_, n = ScanVarint([]byte{0x06, 0x01}) //2 byte negative value, truncated
+	assert.Equal(t, -1, n)
+	_, n = ScanUvarint(nil)
+	assert.Equal(t, -1, n)
+	_, n = ScanUvarint([]byte{0xFF})
+	assert.Equal(t, -1, n)
+}
+
+func TestVarint_ZeroAllocs(t *testing.T) {
+	b := make([]byte, 9)
+	assert.Zero(t, testing.AllocsPerRun(1, func() { PutVarint(b, math.MinInt32) }))
+	assert.Zero(t, testing.AllocsPerRun(1, func() { ScanVarint(b) }))
+	assert.Zero(t, testing.AllocsPerRun(1, func() { PutUvarint(b, math.MaxUint32) }))
+	assert.Zero(t, testing.AllocsPerRun(1, func() { ScanUvarint(b) }))
+}